@@ -2,8 +2,13 @@ module github.com/yone/toggl-daily-summary
 
 go 1.25.5
 
+require (
+	github.com/spf13/cobra v1.8.0
+	golang.org/x/term v0.38.0
+)
+
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/cobra v1.8.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
+	golang.org/x/sys v0.39.0 // indirect
 )
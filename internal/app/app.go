@@ -5,15 +5,25 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/yone/toggl-daily-summary/internal/cache"
 	"github.com/yone/toggl-daily-summary/internal/config"
+	"github.com/yone/toggl-daily-summary/internal/export"
+	"github.com/yone/toggl-daily-summary/internal/ics"
 	"github.com/yone/toggl-daily-summary/internal/summary"
 	"github.com/yone/toggl-daily-summary/internal/toggl"
 )
 
+const defaultBaseURL = "https://api.track.toggl.com/api/v9"
+
+var errMissingAPIToken = errors.New("missing API token: set TOGGL_API_TOKEN or config api_token, api_token_command or api_token_file")
+
 var dateLayouts = []string{
 	"2006-1-2",
 	"2006-01-02",
@@ -26,93 +36,369 @@ type DateRange struct {
 }
 
 func Run(ctx context.Context, opts Options) error {
-	cfg, err := config.Load(opts.ConfigPath)
+	cfg, err := loadConfig(opts.ConfigPath, opts.Profile, opts.WorkspaceID)
 	if err != nil {
 		return err
 	}
-	config.ApplyEnv(&cfg)
-	if opts.WorkspaceID != "" {
-		cfg.WorkspaceID = opts.WorkspaceID
+	if opts.Format != "" {
+		cfg.SetSource("format", "flag --format")
 	}
-	if cfg.BaseURL == "" {
-		cfg.BaseURL = "https://api.track.toggl.com/api/v9"
+	if opts.TaskDelimiter != "" {
+		cfg.SetSource("task_delimiter", "flag --task-delimiter")
+	}
+	if opts.DebugConfig {
+		fmt.Fprint(os.Stderr, config.FormatSources(cfg))
+	}
+	if opts.Input == "" && opts.Replay == "" {
+		if err := config.ResolveToken(&cfg, os.Stderr); err != nil {
+			return err
+		}
 	}
 
-	return run(ctx, opts, cfg, runDeps{
+	deps, err := newRunDeps(opts, cfg)
+	if err != nil {
+		return err
+	}
+
+	return run(ctx, opts, cfg, deps)
+}
+
+func newRunDeps(opts Options, cfg config.Config) (runDeps, error) {
+	deps := runDeps{
 		now:    time.Now,
 		stdout: os.Stdout,
-	})
+		width:  terminalWidth(os.Stdout),
+	}
+	loc, err := cfg.Location()
+	if err != nil {
+		return deps, err
+	}
+	deps.loc = loc
+
+	sources := 0
+	for _, value := range []string{opts.Input, opts.Record, opts.Replay} {
+		if value != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return deps, errors.New("use only one of --input, --record or --replay")
+	}
+
+	switch {
+	case opts.Input != "":
+		source, err := export.Load(opts.Input, loc)
+		if err != nil {
+			return deps, err
+		}
+		deps.client = source
+		deps.offline = true
+	case opts.Replay != "":
+		deps.client = newTogglClient(cfg, toggl.NewReplayHTTPClient(opts.Replay))
+		deps.offline = true
+	case opts.Record != "":
+		deps.client = newTogglClient(cfg, toggl.NewRecordingHTTPClient(opts.Record, nil))
+	case opts.Team:
+		deps.client = newTogglClient(cfg, nil)
+	case !opts.NoCache && cfg.WorkspaceID != "":
+		dir, err := cache.DefaultDir(cfg.APIToken, cfg.WorkspaceID)
+		if err != nil {
+			return deps, err
+		}
+		deps.client = cache.New(newTogglClient(cfg, nil), cache.Options{
+			Dir:      dir,
+			Location: loc,
+			Refresh:  opts.Refresh,
+		})
+	}
+	return deps, nil
+}
+
+func newTogglClient(cfg config.Config, httpClient *http.Client) *toggl.Client {
+	client := toggl.NewClient(cfg.BaseURL, cfg.APIToken, httpClient)
+	client.SetReportsBaseURL(cfg.ReportsBaseURL)
+	return client
+}
+
+func loadConfig(path, profile, workspaceID string) (config.Config, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return config.Config{}, err
+	}
+	if err := config.ApplyProfile(&cfg, profile); err != nil {
+		return config.Config{}, err
+	}
+	config.ApplyEnv(&cfg)
+	if workspaceID != "" {
+		cfg.WorkspaceID = workspaceID
+		cfg.SetSource("workspace_id", "flag --workspace")
+		if len(cfg.Workspaces) > 0 {
+			cfg.Workspaces = []string{workspaceID}
+			cfg.SetSource("workspaces", "flag --workspace")
+		}
+	}
+	if cfg.WorkspaceID == "" && len(cfg.Workspaces) > 0 {
+		cfg.WorkspaceID = cfg.Workspaces[0]
+		cfg.SetSource("workspace_id", cfg.Sources["workspaces"])
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURL
+		cfg.SetSource("base_url", "default")
+	}
+	return cfg, nil
 }
 
 type TogglClient interface {
 	FetchTimeEntries(ctx context.Context, start, end time.Time) ([]toggl.TimeEntry, error)
-	FetchProjects(ctx context.Context, workspaceID string) (map[int64]string, error)
+	FetchProjects(ctx context.Context, workspaceID string) (map[int64]toggl.Project, error)
+	FetchClients(ctx context.Context, workspaceID string) (map[int64]string, error)
+}
+
+type TeamClient interface {
+	FetchWorkspaceTimeEntries(ctx context.Context, workspaceID string, start, end time.Time, userIDs []int64) ([]toggl.TimeEntry, error)
+}
+
+type WorkspaceClient interface {
+	FetchWorkspaces(ctx context.Context) (map[int64]string, error)
 }
 
 type runDeps struct {
-	client TogglClient
-	stdout io.Writer
-	now    func() time.Time
+	client  TogglClient
+	stdout  io.Writer
+	stderr  io.Writer
+	now     func() time.Time
+	loc     *time.Location
+	offline bool
+	width   int
 }
 
 func run(ctx context.Context, opts Options, cfg config.Config, deps runDeps) error {
-	if cfg.APIToken == "" {
-		return errors.New("missing API token: set TOGGL_API_TOKEN or config api_token")
+	deps, err := prepareDeps(cfg, deps)
+	if err != nil {
+		return err
 	}
-	if cfg.WorkspaceID == "" {
-		return errors.New("missing workspace ID: set TOGGL_WORKSPACE_ID or config workspace_id")
+
+	dr, err := resolveDateRange(opts, deps.now, deps.loc)
+	if err != nil {
+		return err
 	}
-	if deps.now == nil {
-		deps.now = time.Now
+
+	formatName := opts.Format
+	if formatName == "" {
+		formatName = cfg.Format
 	}
-	if deps.stdout == nil {
-		deps.stdout = os.Stdout
+	format, err := parseFormat(formatName)
+	if err != nil {
+		return err
 	}
-	if deps.client == nil {
-		deps.client = toggl.NewClient(cfg.BaseURL, cfg.APIToken, nil)
+	if opts.Top < 0 {
+		return fmt.Errorf("invalid --top: %d", opts.Top)
 	}
-
-	dr, err := resolveDateRange(opts, deps.now)
+	if opts.Color && format != "chart" {
+		return errors.New("--color requires --format chart")
+	}
+	if format == "ics" && (opts.OutDir != "" || opts.AppendToNote) {
+		return errors.New("--format ics cannot be used with --out-dir or --append-to-note")
+	}
+	gridRows, gridStyle, err := parseGrid(opts, format)
 	if err != nil {
 		return err
 	}
-
-	format, err := parseFormat(opts.Format)
+	if opts.Team && opts.GroupByWorkspace {
+		return errors.New("use either --team or --group-by-workspace, not both")
+	}
+	post, err := parsePost(opts, cfg)
 	if err != nil {
 		return err
 	}
+	currency := ""
+	if opts.Earnings {
+		if cfg.Rates == nil || strings.TrimSpace(cfg.Rates.Currency) == "" {
+			return errors.New("missing rates: set rates.currency in config to use --earnings")
+		}
+		currency = strings.TrimSpace(cfg.Rates.Currency)
+	}
 
-	timeEntries, err := deps.client.FetchTimeEntries(ctx, dr.Start, dr.End)
+	timeEntries, err := fetchTimeEntries(ctx, opts, cfg, deps, dr)
 	if err != nil {
 		return err
 	}
-	if needsProjectNames(timeEntries) {
-		projects, err := deps.client.FetchProjects(ctx, cfg.WorkspaceID)
+	timeEntries = filterWorkspaces(timeEntries, cfg.Workspaces)
+	if opts.BillableOnly {
+		timeEntries = filterBillable(timeEntries)
+	}
+	var projectColors map[string]string
+	if needsProjectNames(timeEntries) || opts.Earnings || opts.Color {
+		projects, clients, err := fetchProjects(ctx, deps.client, cfg, timeEntries, opts.Earnings && len(cfg.Rates.Clients) > 0)
 		if err != nil {
 			return err
 		}
-		applyProjectNames(timeEntries, projects)
+		applyProjects(timeEntries, projects, clients)
+		if opts.Color {
+			projectColors = colorsByProjectName(projects)
+		}
+	}
+
+	taskDelimiter := opts.TaskDelimiter
+	if taskDelimiter == "" {
+		taskDelimiter = cfg.TaskDelimiter
 	}
 
-	entries := buildSummaryEntries(timeEntries)
-	if opts.Daily {
-		entries = splitEntriesByDay(entries, time.Local)
+	entryOpts := entryOptions{workspaces: opts.GroupByWorkspace}
+	if opts.Earnings {
+		entryOpts.rates = cfg.Rates
+	}
+	if opts.GroupByWorkspace {
+		names, err := fetchWorkspaceNames(ctx, deps.client)
+		if err != nil {
+			return err
+		}
+		entryOpts.workspaceNames = names
 	}
-	buckets := summary.Aggregate(entries, summary.AggregateOptions{
-		Daily:                  opts.Daily,
-		Location:               time.Local,
+	entries := buildSummaryEntries(timeEntries, entryOpts)
+	daily := opts.Daily || opts.AppendToNote || opts.OutDir != "" || format == "grid"
+	aggregateOpts := summary.AggregateOptions{
+		Daily:                  daily,
+		Location:               deps.loc,
 		SeparateTasksByProject: opts.SeparateTaskProjects,
+		TaskDelimiter:          taskDelimiter,
+		GroupByUser:            opts.Team,
+		GroupByWorkspace:       opts.GroupByWorkspace,
+	}
+	var dailyBuckets []summary.Bucket
+	if (format == "html" || format == "chart") && dr.IsRange && !daily {
+		dailyOpts := aggregateOpts
+		dailyOpts.Daily = true
+		dailyBuckets = summary.Aggregate(splitEntriesByDay(entries, deps.loc), dailyOpts)
+	}
+	if daily {
+		entries = splitEntriesByDay(entries, deps.loc)
+	}
+	buckets := summary.Aggregate(entries, aggregateOpts)
+	formatOpts := summary.FormatOptions{
+		Daily:         daily,
+		RangeStart:    dr.Start,
+		RangeEnd:      dr.End,
+		Location:      deps.loc,
+		Format:        format,
+		EmptyMessage:  "No data",
+		Top:           opts.Top,
+		ShowBillable:  opts.ShowBillable,
+		Currency:      currency,
+		Width:         deps.width,
+		Color:         opts.Color,
+		ProjectColors: projectColors,
+		GridRows:      gridRows,
+		GridStyle:     gridStyle,
+		DailyBuckets:  dailyBuckets,
+	}
+
+	var output string
+	if format == "ics" {
+		output = ics.Format(timeEntries, ics.Options{Name: "Toggl", Now: deps.now()})
+	} else if output, err = renderOutput(buckets, formatOpts); err != nil {
+		return err
+	}
+
+	return deliverOutput(ctx, post, opts, cfg, deps, summaryReport{
+		output:     output,
+		markdown:   summary.FormatMarkdown(buckets, formatOpts),
+		buckets:    buckets,
+		formatOpts: formatOpts,
 	})
-	output := summary.FormatMarkdown(buckets, summary.FormatOptions{
-		Daily:        opts.Daily,
-		RangeStart:   dr.Start,
-		RangeEnd:     dr.End,
-		Location:     time.Local,
-		Format:       format,
-		EmptyMessage: "No data",
-	})
+}
 
-	return writeOutput(opts.Out, output, deps.stdout)
+func renderOutput(buckets []summary.Bucket, opts summary.FormatOptions) (string, error) {
+	switch opts.Format {
+	case "html":
+		return summary.FormatHTML(buckets, opts)
+	case "chart":
+		return summary.FormatChart(buckets, opts), nil
+	case "grid":
+		return summary.FormatGrid(buckets, opts)
+	default:
+		return summary.FormatMarkdown(buckets, opts), nil
+	}
+}
+
+func fetchTimeEntries(ctx context.Context, opts Options, cfg config.Config, deps runDeps, dr DateRange) ([]toggl.TimeEntry, error) {
+	if !opts.Team {
+		if len(opts.Users) > 0 {
+			return nil, errors.New("--user requires --team")
+		}
+		return deps.client.FetchTimeEntries(ctx, dr.Start, dr.End)
+	}
+
+	teamClient, ok := deps.client.(TeamClient)
+	if !ok {
+		return nil, errors.New("--team requires the Toggl API and cannot be used with --input")
+	}
+	userIDs, userNames := parseUserFilters(opts.Users)
+	entries, err := teamClient.FetchWorkspaceTimeEntries(ctx, cfg.WorkspaceID, dr.Start, dr.End, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(userNames) == 0 {
+		return entries, nil
+	}
+	out := make([]toggl.TimeEntry, 0, len(entries))
+	for _, entry := range entries {
+		if userNames[strings.ToLower(strings.TrimSpace(entry.UserName))] {
+			out = append(out, entry)
+		}
+	}
+	return out, nil
+}
+
+func parseUserFilters(users []string) ([]int64, map[string]bool) {
+	var ids []int64
+	var names map[string]bool
+	for _, user := range users {
+		user = strings.TrimSpace(user)
+		if user == "" {
+			continue
+		}
+		if id, err := strconv.ParseInt(user, 10, 64); err == nil {
+			ids = append(ids, id)
+			continue
+		}
+		if names == nil {
+			names = map[string]bool{}
+		}
+		names[strings.ToLower(user)] = true
+	}
+	return ids, names
+}
+
+func prepareDeps(cfg config.Config, deps runDeps) (runDeps, error) {
+	if !deps.offline {
+		if cfg.APIToken == "" {
+			return deps, errMissingAPIToken
+		}
+		if cfg.WorkspaceID == "" {
+			return deps, errors.New("missing workspace ID: set TOGGL_WORKSPACE_ID or config workspace_id")
+		}
+	}
+	if deps.now == nil {
+		deps.now = time.Now
+	}
+	if deps.stdout == nil {
+		deps.stdout = os.Stdout
+	}
+	if deps.stderr == nil {
+		deps.stderr = os.Stderr
+	}
+	if deps.loc == nil {
+		loc, err := cfg.Location()
+		if err != nil {
+			return deps, err
+		}
+		deps.loc = loc
+	}
+	if deps.client == nil {
+		deps.client = newTogglClient(cfg, nil)
+	}
+	return deps, nil
 }
 
 func parseFormat(format string) (string, error) {
@@ -122,32 +408,77 @@ func parseFormat(format string) (string, error) {
 		return "default", nil
 	case "detail":
 		return "detail", nil
+	case "html":
+		return "html", nil
+	case "chart":
+		return "chart", nil
+	case "grid":
+		return "grid", nil
+	case "ics":
+		return "ics", nil
 	default:
 		return "", fmt.Errorf("invalid --format: %s", format)
 	}
 }
 
-func resolveDateRange(opts Options, now func() time.Time) (DateRange, error) {
+func parseGrid(opts Options, format string) (string, string, error) {
+	rows := strings.TrimSpace(strings.ToLower(opts.GridRows))
+	style := strings.TrimSpace(strings.ToLower(opts.GridStyle))
+	if format != "grid" {
+		if rows != "" || style != "" {
+			return "", "", errors.New("--grid-rows and --grid-style require --format grid")
+		}
+		return "", "", nil
+	}
+	switch rows {
+	case "", "project":
+		rows = "project"
+	case "task":
+	default:
+		return "", "", fmt.Errorf("invalid --grid-rows: %s", opts.GridRows)
+	}
+	if style == "" {
+		switch strings.ToLower(filepath.Ext(opts.Out)) {
+		case ".csv":
+			style = "csv"
+		case ".html", ".htm":
+			style = "html"
+		default:
+			style = "markdown"
+		}
+	}
+	switch style {
+	case "markdown", "csv", "html":
+	default:
+		return "", "", fmt.Errorf("invalid --grid-style: %s", opts.GridStyle)
+	}
+	return rows, style, nil
+}
+
+func resolveDateRange(opts Options, now func() time.Time, loc *time.Location) (DateRange, error) {
 	if now == nil {
 		now = time.Now
 	}
+	if loc == nil {
+		loc = time.Local
+	}
 	if opts.Date != "" && (opts.From != "" || opts.To != "") {
 		return DateRange{}, errors.New("use either --date or --from/--to, not both")
 	}
 
 	if opts.Date == "" && opts.From == "" && opts.To == "" {
-		current := now().In(time.Local)
-		start := time.Date(current.Year(), current.Month(), current.Day(), 0, 0, 0, 0, time.Local)
+		current := now().In(loc)
+		start := time.Date(current.Year(), current.Month(), current.Day(), 0, 0, 0, 0, loc)
 		end := start.AddDate(0, 0, 1)
 		return DateRange{Start: start, End: end, IsRange: false}, nil
 	}
 
 	if opts.Date != "" {
-		date, err := parseDateInLocation(opts.Date, time.Local)
+		date, err := parseDateInLocation(opts.Date, loc)
 		if err != nil {
 			return DateRange{}, fmt.Errorf("invalid --date: %w", err)
 		}
-		start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+		start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
 		end := start.AddDate(0, 0, 1)
 		return DateRange{Start: start, End: end, IsRange: false}, nil
 	}
@@ -156,11 +487,11 @@ func resolveDateRange(opts Options, now func() time.Time) (DateRange, error) {
 		return DateRange{}, errors.New("both --from and --to are required for a range")
 	}
 
-	from, err := parseDateInLocation(opts.From, time.Local)
+	from, err := parseDateInLocation(opts.From, loc)
 	if err != nil {
 		return DateRange{}, fmt.Errorf("invalid --from: %w", err)
 	}
-	to, err := parseDateInLocation(opts.To, time.Local)
+	to, err := parseDateInLocation(opts.To, loc)
 	if err != nil {
 		return DateRange{}, fmt.Errorf("invalid --to: %w", err)
 	}
@@ -168,8 +499,8 @@ func resolveDateRange(opts Options, now func() time.Time) (DateRange, error) {
 		return DateRange{}, errors.New("--from must be <= --to")
 	}
 
-	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.Local)
-	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
 	return DateRange{Start: start, End: end, IsRange: true}, nil
 }
 
@@ -185,6 +516,78 @@ func parseDateInLocation(value string, loc *time.Location) (time.Time, error) {
 	return time.Time{}, lastErr
 }
 
+func fetchProjects(ctx context.Context, client TogglClient, cfg config.Config, entries []toggl.TimeEntry, withClients bool) (map[int64]toggl.Project, map[int64]string, error) {
+	projects := map[int64]toggl.Project{}
+	var clients map[int64]string
+	if withClients {
+		clients = map[int64]string{}
+	}
+	for _, workspaceID := range workspaceIDs(cfg, entries) {
+		found, err := client.FetchProjects(ctx, workspaceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for id, project := range found {
+			projects[id] = project
+		}
+		if !withClients {
+			continue
+		}
+		names, err := client.FetchClients(ctx, workspaceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for id, name := range names {
+			clients[id] = name
+		}
+	}
+	return projects, clients, nil
+}
+
+func workspaceIDs(cfg config.Config, entries []toggl.TimeEntry) []string {
+	seen := map[string]bool{}
+	var ids []string
+	add := func(id string) {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			return
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	add(cfg.WorkspaceID)
+	for _, id := range cfg.Workspaces {
+		add(id)
+	}
+	for _, entry := range entries {
+		if entry.WorkspaceID != 0 {
+			add(strconv.FormatInt(entry.WorkspaceID, 10))
+		}
+	}
+	if len(ids) == 0 {
+		ids = append(ids, "")
+	}
+	return ids
+}
+
+func fetchWorkspaceNames(ctx context.Context, client TogglClient) (map[int64]string, error) {
+	workspaceClient, ok := client.(WorkspaceClient)
+	if !ok {
+		return nil, nil
+	}
+	return workspaceClient.FetchWorkspaces(ctx)
+}
+
+func colorsByProjectName(projects map[int64]toggl.Project) map[string]string {
+	colors := make(map[string]string, len(projects))
+	for _, project := range projects {
+		if strings.TrimSpace(project.Color) != "" {
+			colors[project.Name] = project.Color
+		}
+	}
+	return colors
+}
+
 func needsProjectNames(entries []toggl.TimeEntry) bool {
 	for _, entry := range entries {
 		if strings.TrimSpace(entry.ProjectName) == "" && entry.ProjectID != 0 {
@@ -194,16 +597,26 @@ func needsProjectNames(entries []toggl.TimeEntry) bool {
 	return false
 }
 
-func applyProjectNames(entries []toggl.TimeEntry, projects map[int64]string) {
+func applyProjects(entries []toggl.TimeEntry, projects map[int64]toggl.Project, clients map[int64]string) {
 	if len(entries) == 0 || len(projects) == 0 {
 		return
 	}
 	for i, entry := range entries {
-		if strings.TrimSpace(entry.ProjectName) != "" || entry.ProjectID == 0 {
+		if entry.ProjectID == 0 {
+			continue
+		}
+		project, ok := projects[entry.ProjectID]
+		if !ok {
 			continue
 		}
-		if name, ok := projects[entry.ProjectID]; ok {
-			entries[i].ProjectName = name
+		if strings.TrimSpace(entry.ProjectName) == "" {
+			entries[i].ProjectName = project.Name
+		}
+		if entry.WorkspaceID == 0 {
+			entries[i].WorkspaceID = project.WorkspaceID
+		}
+		if name, ok := clients[project.ClientID]; ok && project.ClientID != 0 {
+			entries[i].ClientName = name
 		}
 	}
 }
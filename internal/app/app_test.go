@@ -3,13 +3,19 @@ package app
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/yone/toggl-daily-summary/internal/config"
 	"github.com/yone/toggl-daily-summary/internal/toggl"
+	"github.com/yone/toggl-daily-summary/internal/toggl/toggltest"
 )
 
 func TestBuildSummaryEntries(t *testing.T) {
@@ -30,7 +36,7 @@ func TestBuildSummaryEntries(t *testing.T) {
 			ProjectID:   999,
 		},
 	}
-	got := buildSummaryEntries(entries)
+	got := buildSummaryEntries(entries, entryOptions{})
 	if len(got) != 2 {
 		t.Fatalf("expected 2 entries, got %d", len(got))
 	}
@@ -48,6 +54,27 @@ func TestBuildSummaryEntries(t *testing.T) {
 	}
 }
 
+func TestBuildSummaryEntriesCopiesRateAndWorkspace(t *testing.T) {
+	entries := []toggl.TimeEntry{
+		{Description: "Design", Duration: time.Hour, WorkspaceID: 10, ProjectID: 111, ProjectName: "Alpha"},
+		{Description: "Misc", Duration: time.Hour, WorkspaceID: 20},
+		{Description: "Local", Duration: time.Hour},
+	}
+	rates := &config.Rates{Default: 5000, Projects: map[string]float64{"111": 8000}}
+
+	got := buildSummaryEntries(entries, entryOptions{
+		rates:          rates,
+		workspaces:     true,
+		workspaceNames: map[int64]string{10: "Acme"},
+	})
+	if got[0].Rate != 8000 || got[1].Rate != 5000 || got[2].Rate != 5000 {
+		t.Fatalf("unexpected rates: %v / %v / %v", got[0].Rate, got[1].Rate, got[2].Rate)
+	}
+	if got[0].Workspace != "Acme" || got[1].Workspace != "20" || got[2].Workspace != "" {
+		t.Fatalf("unexpected workspaces: %q / %q / %q", got[0].Workspace, got[1].Workspace, got[2].Workspace)
+	}
+}
+
 func TestBuildSummaryEntriesDefaults(t *testing.T) {
 	entries := []toggl.TimeEntry{
 		{
@@ -59,7 +86,7 @@ func TestBuildSummaryEntriesDefaults(t *testing.T) {
 		},
 	}
 
-	got := buildSummaryEntries(entries)
+	got := buildSummaryEntries(entries, entryOptions{})
 	if got[0].Project != "No Project" {
 		t.Fatalf("unexpected default project: %s", got[0].Project)
 	}
@@ -73,7 +100,7 @@ func TestWriteOutput(t *testing.T) {
 
 	t.Run("stdout", func(t *testing.T) {
 		var buf bytes.Buffer
-		if err := writeOutput("", content, &buf); err != nil {
+		if err := writeOutput("", content, &buf, writeCreate); err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
 		if buf.String() != content {
@@ -84,7 +111,7 @@ func TestWriteOutput(t *testing.T) {
 	t.Run("file", func(t *testing.T) {
 		dir := t.TempDir()
 		path := filepath.Join(dir, "out.md")
-		if err := writeOutput(path, content, &bytes.Buffer{}); err != nil {
+		if err := writeOutput(path, content, &bytes.Buffer{}, writeCreate); err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
 		data, err := os.ReadFile(path)
@@ -95,11 +122,65 @@ func TestWriteOutput(t *testing.T) {
 			t.Fatalf("unexpected file content: %s", string(data))
 		}
 	})
+
+	t.Run("unrelated tmp file", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "out.md")
+		if err := os.WriteFile(path+".tmp", []byte("keep me"), 0o644); err != nil {
+			t.Fatalf("unexpected write error: %v", err)
+		}
+		if err := writeOutput(path, content, &bytes.Buffer{}, writeCreate); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if data, _ := os.ReadFile(path + ".tmp"); string(data) != "keep me" {
+			t.Fatalf("expected %s.tmp to be left alone, got %q", path, data)
+		}
+	})
+
+	t.Run("existing file", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "out.md")
+		if err := os.WriteFile(path, []byte("old\n"), 0o600); err != nil {
+			t.Fatalf("unexpected write error: %v", err)
+		}
+
+		err := writeOutput(path, content, &bytes.Buffer{}, writeCreate)
+		if err == nil || !strings.Contains(err.Error(), "use --force") {
+			t.Fatalf("expected overwrite refusal, got %v", err)
+		}
+		if err := writeOutput(path, content, &bytes.Buffer{}, writeAppend); err != nil {
+			t.Fatalf("unexpected append error: %v", err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != "old\nhello" {
+			t.Fatalf("unexpected appended content: %q", data)
+		}
+		if err := writeOutput(path, content, &bytes.Buffer{}, writeOverwrite); err != nil {
+			t.Fatalf("unexpected overwrite error: %v", err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != content {
+			t.Fatalf("unexpected overwritten content: %q", data)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("unexpected stat error: %v", err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Fatalf("expected permissions to be kept, got %v", info.Mode().Perm())
+		}
+		entries, err := os.ReadDir(filepath.Dir(path))
+		if err != nil {
+			t.Fatalf("unexpected readdir error: %v", err)
+		}
+		if len(entries) != 1 || entries[0].Name() != filepath.Base(path) {
+			t.Fatalf("expected temp files to be gone, got %v", entries)
+		}
+	})
 }
 
 type fakeTogglClient struct {
 	timeEntries []toggl.TimeEntry
-	projects    map[int64]string
+	projects    map[int64]toggl.Project
+	clients     map[int64]string
 }
 
 func (f *fakeTogglClient) FetchTimeEntries(_ context.Context, start, end time.Time) ([]toggl.TimeEntry, error) {
@@ -108,14 +189,22 @@ func (f *fakeTogglClient) FetchTimeEntries(_ context.Context, start, end time.Ti
 	return f.timeEntries, nil
 }
 
-func (f *fakeTogglClient) FetchProjects(_ context.Context, workspaceID string) (map[int64]string, error) {
+func (f *fakeTogglClient) FetchProjects(_ context.Context, workspaceID string) (map[int64]toggl.Project, error) {
 	_ = workspaceID
 	if f.projects == nil {
-		return map[int64]string{}, nil
+		return map[int64]toggl.Project{}, nil
 	}
 	return f.projects, nil
 }
 
+func (f *fakeTogglClient) FetchClients(_ context.Context, workspaceID string) (map[int64]string, error) {
+	_ = workspaceID
+	if f.clients == nil {
+		return map[int64]string{}, nil
+	}
+	return f.clients, nil
+}
+
 func TestRunWritesSummary(t *testing.T) {
 	client := &fakeTogglClient{
 		timeEntries: []toggl.TimeEntry{
@@ -211,8 +300,8 @@ func TestRunResolvesProjectNamesFromWorkspace(t *testing.T) {
 				ProjectID:   111,
 			},
 		},
-		projects: map[int64]string{
-			111: "Alpha",
+		projects: map[int64]toggl.Project{
+			111: {ID: 111, Name: "Alpha"},
 		},
 	}
 
@@ -311,3 +400,1144 @@ func TestRunSplitsEntriesAcrossDaysWhenDaily(t *testing.T) {
 		t.Fatalf("unexpected output:\n--- got ---\n%s\n--- want ---\n%s", buf.String(), want)
 	}
 }
+
+func TestRunNegativeTopReturnsError(t *testing.T) {
+	opts := Options{
+		Date: "2026-01-10",
+		Top:  -1,
+	}
+	cfg := config.Config{
+		APIToken:    "token",
+		WorkspaceID: "999",
+		BaseURL:     "http://example",
+	}
+
+	err := run(context.Background(), opts, cfg, runDeps{
+		client: &fakeTogglClient{},
+		now: func() time.Time {
+			return time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+		},
+	})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestRunBillableOnlyFiltersEntries(t *testing.T) {
+	client := &fakeTogglClient{
+		timeEntries: []toggl.TimeEntry{
+			{
+				Description: "Design",
+				Start:       time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC),
+				Duration:    90 * time.Minute,
+				ProjectName: "Alpha",
+				Billable:    true,
+			},
+			{
+				Description: "Meeting",
+				Start:       time.Date(2026, 1, 10, 11, 0, 0, 0, time.UTC),
+				Duration:    30 * time.Minute,
+				ProjectName: "Alpha",
+			},
+		},
+	}
+	opts := Options{
+		Date:         "2026-01-10",
+		BillableOnly: true,
+	}
+	cfg := config.Config{
+		APIToken:    "token",
+		WorkspaceID: "999",
+		BaseURL:     "http://example",
+	}
+
+	var buf bytes.Buffer
+	err := run(context.Background(), opts, cfg, runDeps{
+		client: client,
+		stdout: &buf,
+		now: func() time.Time {
+			return time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "" +
+		"### タスク\n" +
+		"- Design 1.50h\n" +
+		"\n" +
+		"### プロジェクト\n" +
+		"- Alpha 1.50h\n"
+
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n--- got ---\n%s\n--- want ---\n%s", buf.String(), want)
+	}
+}
+
+func TestRunEarningsUsesRatePrecedence(t *testing.T) {
+	client := &fakeTogglClient{
+		timeEntries: []toggl.TimeEntry{
+			{
+				Description: "Design",
+				Start:       time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC),
+				Duration:    2 * time.Hour,
+				ProjectID:   111,
+				WorkspaceID: 999,
+			},
+			{
+				Description: "Support",
+				Start:       time.Date(2026, 1, 10, 11, 0, 0, 0, time.UTC),
+				Duration:    time.Hour,
+				ProjectID:   222,
+				WorkspaceID: 999,
+			},
+			{
+				Description: "Admin",
+				Start:       time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC),
+				Duration:    time.Hour,
+				WorkspaceID: 999,
+			},
+		},
+		projects: map[int64]toggl.Project{
+			111: {ID: 111, Name: "Alpha", ClientID: 5},
+			222: {ID: 222, Name: "Beta", ClientID: 5},
+		},
+		clients: map[int64]string{
+			5: "Acme",
+		},
+	}
+	opts := Options{
+		Date:     "2026-01-10",
+		Earnings: true,
+	}
+	cfg := config.Config{
+		APIToken:    "token",
+		WorkspaceID: "999",
+		BaseURL:     "http://example",
+		Rates: &config.Rates{
+			Currency:   "JPY",
+			Default:    1000,
+			Workspaces: map[string]float64{"999": 2000},
+			Clients:    map[string]float64{"Acme": 3000},
+			Projects:   map[string]float64{"Alpha": 5000},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := run(context.Background(), opts, cfg, runDeps{
+		client: client,
+		stdout: &buf,
+		now: func() time.Time {
+			return time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "" +
+		"### タスク\n" +
+		"- Design 2.00h JPY 10000.00\n" +
+		"- Support 1.00h JPY 3000.00\n" +
+		"- Admin 1.00h JPY 2000.00\n" +
+		"\n" +
+		"### プロジェクト\n" +
+		"- Alpha 2.00h JPY 10000.00\n" +
+		"- Beta 1.00h JPY 3000.00\n" +
+		"- No Project 1.00h JPY 2000.00\n" +
+		"\n" +
+		"### 合計\n" +
+		"- 4.00h JPY 15000.00\n"
+
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n--- got ---\n%s\n--- want ---\n%s", buf.String(), want)
+	}
+}
+
+func TestRunEarningsWithoutRatesReturnsError(t *testing.T) {
+	opts := Options{
+		Date:     "2026-01-10",
+		Earnings: true,
+	}
+	cfg := config.Config{
+		APIToken:    "token",
+		WorkspaceID: "999",
+		BaseURL:     "http://example",
+	}
+
+	err := run(context.Background(), opts, cfg, runDeps{
+		client: &fakeTogglClient{},
+		now: func() time.Time {
+			return time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+		},
+	})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestRunInvoiceFiltersClient(t *testing.T) {
+	client := &fakeTogglClient{
+		timeEntries: []toggl.TimeEntry{
+			{
+				Description: "Design",
+				Start:       time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC),
+				Duration:    2 * time.Hour,
+				ProjectID:   111,
+			},
+			{
+				Description: "Other",
+				Start:       time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC),
+				Duration:    time.Hour,
+				ProjectID:   222,
+			},
+		},
+		projects: map[int64]toggl.Project{
+			111: {ID: 111, Name: "Alpha", ClientID: 5},
+			222: {ID: 222, Name: "Beta", ClientID: 6},
+		},
+		clients: map[int64]string{
+			5: "Acme",
+			6: "Globex",
+		},
+	}
+	opts := InvoiceOptions{
+		From:   "2026-01-01",
+		To:     "2026-01-31",
+		Client: "Acme",
+		Number: "INV-0042",
+	}
+	cfg := config.Config{
+		APIToken:    "token",
+		WorkspaceID: "999",
+		BaseURL:     "http://example",
+		Rates: &config.Rates{
+			Currency: "JPY",
+			Default:  5000,
+		},
+		Invoice: &config.Invoice{
+			Issuer:  config.Party{Name: "Yone"},
+			TaxRate: 0.1,
+		},
+	}
+
+	var buf bytes.Buffer
+	err := runInvoice(context.Background(), opts, cfg, runDeps{
+		client: client,
+		stdout: &buf,
+		now: func() time.Time {
+			return time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := buf.String()
+	if !strings.Contains(got, "# 請求書 INV-0042") {
+		t.Fatalf("missing invoice number:\n%s", got)
+	}
+	if !strings.Contains(got, "| Alpha | 2.00h | JPY 5000.00 | JPY 10000.00 |") {
+		t.Fatalf("missing Alpha line:\n%s", got)
+	}
+	if strings.Contains(got, "Beta") {
+		t.Fatalf("unexpected line for other client:\n%s", got)
+	}
+	if !strings.Contains(got, "- 合計: JPY 11000.00") {
+		t.Fatalf("unexpected total:\n%s", got)
+	}
+}
+
+func TestRunInvoiceCommitsSequenceOnlyAfterWrite(t *testing.T) {
+	dir := t.TempDir()
+	seqPath := filepath.Join(dir, "invoice-sequence")
+	client := &fakeTogglClient{
+		timeEntries: []toggl.TimeEntry{
+			{Description: "Design", Start: time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC), Duration: 2 * time.Hour, ProjectID: 111},
+		},
+		projects: map[int64]toggl.Project{111: {ID: 111, Name: "Alpha"}},
+	}
+	cfg := config.Config{
+		APIToken:    "token",
+		WorkspaceID: "999",
+		Rates:       &config.Rates{Currency: "JPY", Default: 5000},
+		Invoice:     &config.Invoice{SequenceFile: seqPath},
+	}
+	deps := runDeps{
+		client: client,
+		stdout: &bytes.Buffer{},
+		now:    func() time.Time { return time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC) },
+	}
+
+	opts := InvoiceOptions{From: "2026-01-01", To: "2026-01-31", Out: filepath.Join(dir, "missing", "invoice.md")}
+	if err := runInvoice(context.Background(), opts, cfg, deps); err == nil {
+		t.Fatal("expected write error")
+	}
+	if _, err := os.Stat(seqPath); !os.IsNotExist(err) {
+		t.Fatalf("expected sequence to stay unused after a failed write, got %v", err)
+	}
+
+	opts.Out = filepath.Join(dir, "invoice.md")
+	if err := runInvoice(context.Background(), opts, cfg, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(opts.Out)
+	if err != nil {
+		t.Fatalf("read invoice: %v", err)
+	}
+	if !strings.Contains(string(data), "# 請求書 INV-0001") {
+		t.Fatalf("expected first number after failed attempt:\n%s", data)
+	}
+	if seq, _ := os.ReadFile(seqPath); strings.TrimSpace(string(seq)) != "1" {
+		t.Fatalf("unexpected sequence file: %q", seq)
+	}
+}
+
+func TestRunBudgetWarnsOnThreshold(t *testing.T) {
+	client := &fakeTogglClient{
+		timeEntries: []toggl.TimeEntry{
+			{
+				Description: "Build",
+				Start:       time.Date(2026, 1, 9, 9, 0, 0, 0, time.Local),
+				Duration:    9 * time.Hour,
+				ProjectID:   111,
+			},
+		},
+		projects: map[int64]toggl.Project{
+			111: {ID: 111, Name: "Alpha"},
+		},
+	}
+	cfg := config.Config{
+		APIToken:    "token",
+		WorkspaceID: "999",
+		BaseURL:     "http://example",
+		Budgets: []config.Budget{
+			{Project: "111", MonthlyHours: 10},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	err := runBudget(context.Background(), BudgetOptions{Date: "2026-01-10"}, cfg, runDeps{
+		client: client,
+		stdout: &stdout,
+		stderr: &stderr,
+		now: func() time.Time {
+			return time.Date(2026, 1, 10, 0, 0, 0, 0, time.Local)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "| 2026-01 | 10.00h | 9.00h | 1.00h | 90.0% ⚠ |") {
+		t.Fatalf("unexpected report:\n%s", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "warning: Alpha 2026-01") {
+		t.Fatalf("expected warning, got: %s", stderr.String())
+	}
+}
+
+func TestRunOfflineDoesNotRequireToken(t *testing.T) {
+	client := &fakeTogglClient{
+		timeEntries: []toggl.TimeEntry{
+			{
+				Description: "Design",
+				Start:       time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC),
+				Duration:    time.Hour,
+				ProjectName: "Alpha",
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := run(context.Background(), Options{Date: "2026-01-10"}, config.Config{}, runDeps{
+		client:  client,
+		stdout:  &buf,
+		offline: true,
+		now: func() time.Time {
+			return time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "- Alpha 1.00h") {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestRunAgainstFakeServer(t *testing.T) {
+	server := toggltest.NewServer()
+	defer server.Close()
+	server.AddTimeEntries(toggl.TimeEntry{
+		ID:          1,
+		Description: "Design",
+		Start:       time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC),
+		Duration:    90 * time.Minute,
+		ProjectID:   111,
+	})
+	server.AddProjects("999", toggl.Project{ID: 111, WorkspaceID: 999, Name: "Alpha"})
+
+	cfg := config.Config{
+		APIToken:    "token",
+		WorkspaceID: "999",
+		BaseURL:     server.URL,
+	}
+
+	var buf bytes.Buffer
+	err := run(context.Background(), Options{Date: "2026-01-10"}, cfg, runDeps{
+		client: toggl.NewClient(cfg.BaseURL, cfg.APIToken, server.Client()),
+		stdout: &buf,
+		now: func() time.Time {
+			return time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "- Alpha 1.50h") {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestRunTeamFiltersUsersByName(t *testing.T) {
+	server := toggltest.NewServer()
+	defer server.Close()
+	server.AddTimeEntries(
+		toggl.TimeEntry{
+			ID:          1,
+			Description: "Design",
+			Start:       time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC),
+			Duration:    time.Hour,
+			ProjectID:   111,
+			UserID:      1,
+			UserName:    "Alice",
+		},
+		toggl.TimeEntry{
+			ID:          2,
+			Description: "Review",
+			Start:       time.Date(2026, 1, 10, 10, 0, 0, 0, time.UTC),
+			Duration:    time.Hour,
+			ProjectID:   111,
+			UserID:      2,
+			UserName:    "Bob",
+		},
+	)
+	server.AddProjects("999", toggl.Project{ID: 111, WorkspaceID: 999, Name: "Alpha"})
+
+	cfg := config.Config{
+		APIToken:    "token",
+		WorkspaceID: "999",
+		BaseURL:     server.URL,
+	}
+
+	var buf bytes.Buffer
+	err := run(context.Background(), Options{Date: "2026-01-10", Team: true, Users: []string{"alice"}}, cfg, runDeps{
+		client: toggl.NewClient(cfg.BaseURL, cfg.APIToken, server.Client()),
+		stdout: &buf,
+		now: func() time.Time {
+			return time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "" +
+		"### Alice 1.00h\n" +
+		"- Alpha 1.00h\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n--- got ---\n%s\n--- want ---\n%s", buf.String(), want)
+	}
+}
+
+func TestRunTeamRequiresAPIClient(t *testing.T) {
+	err := run(context.Background(), Options{Date: "2026-01-10", Team: true}, config.Config{}, runDeps{
+		client:  &fakeTogglClient{},
+		offline: true,
+		now: func() time.Time {
+			return time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+		},
+	})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestRunResolvesProjectsAcrossWorkspaces(t *testing.T) {
+	server := toggltest.NewServer()
+	defer server.Close()
+	server.AddTimeEntries(
+		toggl.TimeEntry{
+			ID:          1,
+			WorkspaceID: 999,
+			Description: "Design",
+			Start:       time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC),
+			Duration:    time.Hour,
+			ProjectID:   111,
+		},
+		toggl.TimeEntry{
+			ID:          2,
+			WorkspaceID: 888,
+			Description: "Support",
+			Start:       time.Date(2026, 1, 10, 10, 0, 0, 0, time.UTC),
+			Duration:    2 * time.Hour,
+			ProjectID:   222,
+		},
+		toggl.TimeEntry{
+			ID:          3,
+			WorkspaceID: 777,
+			Description: "Side project",
+			Start:       time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC),
+			Duration:    time.Hour,
+			ProjectID:   333,
+		},
+	)
+	server.AddProjects("999", toggl.Project{ID: 111, WorkspaceID: 999, Name: "Alpha"})
+	server.AddProjects("888", toggl.Project{ID: 222, WorkspaceID: 888, Name: "Helpdesk"})
+	server.AddWorkspace(999, "Personal")
+	server.AddWorkspace(888, "Company")
+
+	cfg := config.Config{
+		APIToken:    "token",
+		WorkspaceID: "999",
+		Workspaces:  []string{"999", "888"},
+		BaseURL:     server.URL,
+	}
+
+	var buf bytes.Buffer
+	err := run(context.Background(), Options{Date: "2026-01-10", GroupByWorkspace: true}, cfg, runDeps{
+		client: toggl.NewClient(cfg.BaseURL, cfg.APIToken, server.Client()),
+		stdout: &buf,
+		now: func() time.Time {
+			return time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "" +
+		"### Company 2.00h\n" +
+		"- Helpdesk 2.00h\n" +
+		"\n" +
+		"### Personal 1.00h\n" +
+		"- Alpha 1.00h\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n--- got ---\n%s\n--- want ---\n%s", buf.String(), want)
+	}
+}
+
+func TestLoadConfigAppliesProfileThenEnvThenFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+  "api_token": "file-token",
+  "workspace_id": "111",
+  "default_profile": "personal",
+  "profiles": {
+    "personal": {"api_token": "personal-token", "workspace_id": "222", "timezone": "Asia/Tokyo"},
+    "work": {"api_token": "work-token", "workspace_id": "333", "format": "detail"}
+  }
+}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	t.Setenv("TOGGL_API_TOKEN", "")
+	t.Setenv("TOGGL_WORKSPACE_ID", "")
+	t.Setenv("TOGGL_BASE_URL", "")
+	t.Setenv("TOGGL_TIMEZONE", "")
+	t.Setenv("TOGGL_PROFILE", "work")
+
+	cfg, err := loadConfig(path, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Profile != "work" || cfg.APIToken != "work-token" || cfg.WorkspaceID != "333" || cfg.Format != "detail" || cfg.Timezone != "" {
+		t.Fatalf("unexpected config from TOGGL_PROFILE: %+v", cfg)
+	}
+
+	t.Setenv("TOGGL_API_TOKEN", "env-token")
+	cfg, err = loadConfig(path, "personal", "444")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Profile != "personal" || cfg.APIToken != "env-token" || cfg.WorkspaceID != "444" || cfg.Timezone != "Asia/Tokyo" {
+		t.Fatalf("unexpected config with flags: %+v", cfg)
+	}
+
+	got := config.FormatSources(cfg)
+	for _, want := range []string{
+		"profile: personal (flag --profile)\n",
+		"api_token: env TOGGL_API_TOKEN\n",
+		"workspace_id: flag --workspace\n",
+		"base_url: default\n",
+		"timezone: profile personal\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in sources:\n%s", want, got)
+		}
+	}
+
+	if _, err := loadConfig(path, "missing", ""); err == nil {
+		t.Fatalf("expected error for unknown profile")
+	}
+}
+
+func TestLoadConfigWorkspaceEnvNarrowsWorkspaces(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+  "api_token": "file-token",
+  "profiles": {
+    "team": {"workspaces": ["111", "222"]}
+  }
+}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	t.Setenv("TOGGL_API_TOKEN", "")
+	t.Setenv("TOGGL_BASE_URL", "")
+	t.Setenv("TOGGL_TIMEZONE", "")
+	t.Setenv("TOGGL_PROFILE", "")
+	t.Setenv("TOGGL_WORKSPACE_ID", "222")
+
+	cfg, err := loadConfig(path, "team", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.WorkspaceID != "222" || len(cfg.Workspaces) != 1 || cfg.Workspaces[0] != "222" {
+		t.Fatalf("expected env workspace to narrow workspaces: %+v", cfg)
+	}
+	if cfg.Sources["workspaces"] != "env TOGGL_WORKSPACE_ID" {
+		t.Fatalf("unexpected workspaces source: %q", cfg.Sources["workspaces"])
+	}
+
+	entries := filterWorkspaces([]toggl.TimeEntry{{ID: 1, WorkspaceID: 111}, {ID: 2, WorkspaceID: 222}}, cfg.Workspaces)
+	if len(entries) != 1 || entries[0].ID != 2 {
+		t.Fatalf("expected entries from the env workspace, got %+v", entries)
+	}
+}
+
+func TestRunUsesConfiguredTimezone(t *testing.T) {
+	client := &fakeTogglClient{
+		timeEntries: []toggl.TimeEntry{
+			{
+				Description: "Late",
+				Start:       time.Date(2026, 1, 9, 16, 0, 0, 0, time.UTC),
+				Duration:    time.Hour,
+				ProjectName: "Alpha",
+			},
+		},
+	}
+	cfg := config.Config{
+		APIToken:    "token",
+		WorkspaceID: "999",
+		Timezone:    "Asia/Tokyo",
+		Format:      "detail",
+	}
+
+	var buf bytes.Buffer
+	err := run(context.Background(), Options{From: "2026-01-10", To: "2026-01-10", Daily: true}, cfg, runDeps{
+		client: client,
+		stdout: &buf,
+		now: func() time.Time {
+			return time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "" +
+		"## 2026-01-10\n" +
+		"\n" +
+		"### Alpha 1.00h\n" +
+		"- Late 1.00h\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n--- got ---\n%s\n--- want ---\n%s", buf.String(), want)
+	}
+}
+
+func TestRunPostsSummaryToSlack(t *testing.T) {
+	var posted []string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		posted = append(posted, string(data))
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer webhook.Close()
+
+	client := &fakeTogglClient{
+		timeEntries: []toggl.TimeEntry{
+			{
+				Description: "Design",
+				Start:       time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC),
+				Duration:    90 * time.Minute,
+				ProjectName: "Alpha",
+			},
+		},
+	}
+	cfg := config.Config{
+		APIToken:    "token",
+		WorkspaceID: "999",
+		Slack:       &config.Slack{WebhookURL: webhook.URL},
+	}
+	now := func() time.Time { return time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC) }
+
+	var buf bytes.Buffer
+	err := run(context.Background(), Options{Date: "2026-01-10", Post: "slack"}, cfg, runDeps{client: client, stdout: &buf, now: now})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected nothing on stdout, got:\n%s", buf.String())
+	}
+	if len(posted) != 1 || !strings.Contains(posted[0], `"text":"*タスク*\n• Design *1.50h*"`) {
+		t.Fatalf("unexpected payloads: %v", posted)
+	}
+
+	buf.Reset()
+	err = run(context.Background(), Options{Date: "2026-01-10", Post: "slack", DryRun: true}, cfg, runDeps{client: client, stdout: &buf, now: now})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(posted) != 1 {
+		t.Fatalf("dry run must not post, got %d payloads", len(posted))
+	}
+	if !strings.Contains(buf.String(), `"type": "mrkdwn"`) {
+		t.Fatalf("expected payload on stdout, got:\n%s", buf.String())
+	}
+
+	cfg.Slack = nil
+	err = run(context.Background(), Options{Date: "2026-01-10", Post: "slack"}, cfg, runDeps{client: client, stdout: &buf, now: now})
+	if err == nil || !strings.Contains(err.Error(), "slack.webhook_url") {
+		t.Fatalf("expected missing webhook error, got %v", err)
+	}
+}
+
+func TestRunPostsSummaryToWebhook(t *testing.T) {
+	var got map[string]any
+	var signature, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Signature-256")
+		contentType = r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatalf("invalid body: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := &fakeTogglClient{
+		timeEntries: []toggl.TimeEntry{
+			{
+				Description: "Design",
+				Start:       time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC),
+				Duration:    90 * time.Minute,
+				ProjectName: "Alpha",
+			},
+		},
+	}
+	cfg := config.Config{
+		APIToken:    "token",
+		WorkspaceID: "999",
+		Webhook:     &config.Webhook{URL: server.URL, Secret: "s3cret"},
+	}
+	now := func() time.Time { return time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC) }
+
+	var buf bytes.Buffer
+	err := run(context.Background(), Options{Date: "2026-01-10", Post: "webhook"}, cfg, runDeps{client: client, stdout: &buf, now: now, loc: time.UTC})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(signature, "sha256=") {
+		t.Fatalf("expected signed request, got %q", signature)
+	}
+	if contentType != "application/json" {
+		t.Fatalf("expected JSON content type, got %q", contentType)
+	}
+	total, _ := got["total"].(map[string]any)
+	if total["seconds"] != float64(5400) || got["markdown"] == "" {
+		t.Fatalf("unexpected payload: %v", got)
+	}
+	if rng, _ := got["range"].(map[string]any); rng["start"] != "2026-01-10" || rng["end"] != "2026-01-10" {
+		t.Fatalf("unexpected range: %v", got["range"])
+	}
+
+	cfg.Webhook.BodyTemplate = `{"hours": {{.Total.Hours}}}`
+	err = run(context.Background(), Options{Date: "2026-01-10", Post: "webhook", DryRun: true}, cfg, runDeps{client: client, stdout: &buf, now: now, loc: time.UTC})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "{\"hours\": 1.5}\n" {
+		t.Fatalf("unexpected dry run output: %q", buf.String())
+	}
+}
+
+func TestRunDryRunPrintsEmail(t *testing.T) {
+	client := &fakeTogglClient{
+		timeEntries: []toggl.TimeEntry{
+			{
+				Description: "Design",
+				Start:       time.Date(2026, 1, 6, 9, 0, 0, 0, time.UTC),
+				Duration:    90 * time.Minute,
+				ProjectName: "Alpha",
+			},
+		},
+	}
+	cfg := config.Config{
+		APIToken:    "token",
+		WorkspaceID: "999",
+		Email: &config.Email{
+			Host:    "smtp.example.com",
+			From:    "bot@example.com",
+			To:      []string{"boss@example.com"},
+			Subject: "Weekly {{.Range}} {{.Total}}",
+		},
+	}
+
+	var buf bytes.Buffer
+	err := run(context.Background(), Options{From: "2026-01-05", To: "2026-01-11", Post: "email", DryRun: true}, cfg, runDeps{
+		client: client,
+		stdout: &buf,
+		now:    func() time.Time { return time.Date(2026, 1, 11, 18, 0, 0, 0, time.UTC) },
+		loc:    time.UTC,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"To: <boss@example.com>\r\n",
+		"Subject: Weekly 2026-01-05..2026-01-11 1.50h\r\n",
+		"Content-Type: multipart/alternative;",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Type: text/html; charset=utf-8",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in message:\n%s", want, got)
+		}
+	}
+
+	cfg.Email.From = ""
+	err = run(context.Background(), Options{From: "2026-01-05", To: "2026-01-11", Post: "email", DryRun: true}, cfg, runDeps{
+		client: client,
+		stdout: &bytes.Buffer{},
+		now:    func() time.Time { return time.Date(2026, 1, 11, 18, 0, 0, 0, time.UTC) },
+		loc:    time.UTC,
+	})
+	if err == nil || !strings.Contains(err.Error(), "email.from") {
+		t.Fatalf("expected missing sender error, got %v", err)
+	}
+}
+
+func TestRunAppendsSummaryToDailyNotes(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "template.md")
+	if err := os.WriteFile(templatePath, []byte("# {{date}}\n\n## Journal\n"), 0o644); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	existing := filepath.Join(dir, "daily", "2026-01-11.md")
+	if err := os.MkdirAll(filepath.Dir(existing), 0o755); err != nil {
+		t.Fatalf("unexpected mkdir error: %v", err)
+	}
+	if err := os.WriteFile(existing, []byte("# 2026-01-11\n\n## Time\n\n## Journal\nShipped\n"), 0o644); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+
+	client := &fakeTogglClient{
+		timeEntries: []toggl.TimeEntry{
+			{Description: "Design", Start: time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC), Duration: time.Hour, ProjectName: "Alpha"},
+			{Description: "Build", Start: time.Date(2026, 1, 11, 9, 0, 0, 0, time.UTC), Duration: 30 * time.Minute, ProjectName: "Alpha"},
+		},
+	}
+	cfg := config.Config{
+		APIToken:    "token",
+		WorkspaceID: "999",
+		Notes: &config.Notes{
+			Path:     filepath.Join(dir, "daily", "{{date}}.md"),
+			Heading:  "## Time",
+			Template: templatePath,
+		},
+	}
+	opts := Options{From: "2026-01-10", To: "2026-01-12", AppendToNote: true}
+	deps := runDeps{
+		client: client,
+		now:    func() time.Time { return time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC) },
+		loc:    time.UTC,
+	}
+
+	var buf bytes.Buffer
+	deps.stdout = &buf
+	if err := run(context.Background(), opts, cfg, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	created, err := os.ReadFile(filepath.Join(dir, "daily", "2026-01-10.md"))
+	if err != nil {
+		t.Fatalf("expected note to be created: %v", err)
+	}
+	wantCreated := "# 2026-01-10\n\n## Journal\n\n## Time\n\n" +
+		"<!-- toggl-daily-summary:start -->\n" +
+		"### タスク\n- Design 1.00h\n\n### プロジェクト\n- Alpha 1.00h\n" +
+		"<!-- toggl-daily-summary:end -->\n"
+	if string(created) != wantCreated {
+		t.Fatalf("unexpected created note:\n%s", created)
+	}
+	updated, err := os.ReadFile(existing)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	if !strings.Contains(string(updated), "## Time\n\n<!-- toggl-daily-summary:start -->\n### タスク\n- Build 0.50h\n") || !strings.HasSuffix(string(updated), "## Journal\nShipped\n") {
+		t.Fatalf("unexpected updated note:\n%s", updated)
+	}
+
+	buf.Reset()
+	if err := run(context.Background(), opts, cfg, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Count(buf.String(), "Unchanged ") != 2 {
+		t.Fatalf("expected idempotent second run, got:\n%s", buf.String())
+	}
+}
+
+func TestRunWritesOneFilePerDayToOutDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	client := &fakeTogglClient{
+		timeEntries: []toggl.TimeEntry{
+			{Description: "Design", Start: time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC), Duration: time.Hour, ProjectName: "Alpha"},
+			{Description: "Build", Start: time.Date(2026, 1, 11, 9, 0, 0, 0, time.UTC), Duration: 30 * time.Minute, ProjectName: "Alpha"},
+		},
+	}
+	cfg := config.Config{APIToken: "token", WorkspaceID: "999"}
+	opts := Options{From: "2026-01-10", To: "2026-01-11", OutDir: dir, OutName: "summary-{{date}}.md"}
+	deps := runDeps{
+		client: client,
+		stdout: &bytes.Buffer{},
+		now:    func() time.Time { return time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC) },
+		loc:    time.UTC,
+	}
+
+	if err := run(context.Background(), opts, cfg, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "summary-2026-01-11.md"))
+	if err != nil {
+		t.Fatalf("expected per-day file: %v", err)
+	}
+	if string(data) != "### タスク\n- Build 0.50h\n\n### プロジェクト\n- Alpha 0.50h\n" {
+		t.Fatalf("unexpected file content:\n%s", data)
+	}
+
+	err = run(context.Background(), opts, cfg, deps)
+	if err == nil || !strings.Contains(err.Error(), "summary-2026-01-10.md already exists") {
+		t.Fatalf("expected overwrite refusal, got %v", err)
+	}
+	opts.Force = true
+	if err := run(context.Background(), opts, cfg, deps); err != nil {
+		t.Fatalf("unexpected error with --force: %v", err)
+	}
+}
+
+func TestRunWritesHTMLFormat(t *testing.T) {
+	client := &fakeTogglClient{
+		timeEntries: []toggl.TimeEntry{
+			{Description: "Design", Start: time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC), Duration: time.Hour, ProjectName: "Alpha"},
+			{Description: "Build", Start: time.Date(2026, 1, 11, 9, 0, 0, 0, time.UTC), Duration: 30 * time.Minute, ProjectName: "Beta"},
+		},
+	}
+	cfg := config.Config{APIToken: "token", WorkspaceID: "999"}
+	var stdout bytes.Buffer
+	deps := runDeps{
+		client: client,
+		stdout: &stdout,
+		now:    func() time.Time { return time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC) },
+		loc:    time.UTC,
+	}
+
+	if err := run(context.Background(), Options{From: "2026-01-10", To: "2026-01-11", Daily: true, Format: "html"}, cfg, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := stdout.String()
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>Toggl summary 2026-01-10..2026-01-11</title>",
+		`<svg xmlns="http://www.w3.org/2000/svg" class="bars"`,
+		`<svg xmlns="http://www.w3.org/2000/svg" class="pie"`,
+		"<h2>2026-01-11</h2>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "<script") || strings.Contains(got, "<link") || strings.Contains(got, "src=") {
+		t.Fatalf("expected self-contained html:\n%s", got)
+	}
+}
+
+func TestRunWritesHTMLBarsPerDayWithoutDaily(t *testing.T) {
+	client := &fakeTogglClient{
+		timeEntries: []toggl.TimeEntry{
+			{Description: "Design", Start: time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC), Duration: time.Hour, ProjectName: "Alpha"},
+			{Description: "Build", Start: time.Date(2026, 1, 11, 9, 0, 0, 0, time.UTC), Duration: 30 * time.Minute, ProjectName: "Beta"},
+		},
+	}
+	cfg := config.Config{APIToken: "token", WorkspaceID: "999"}
+	var stdout bytes.Buffer
+	deps := runDeps{
+		client: client,
+		stdout: &stdout,
+		now:    func() time.Time { return time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC) },
+		loc:    time.UTC,
+	}
+
+	if err := run(context.Background(), Options{From: "2026-01-10", To: "2026-01-11", Format: "html"}, cfg, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := stdout.String()
+	for _, want := range []string{">01-10</text>", ">01-11</text>"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected a bar labelled %q in output:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, ">Total</text>") {
+		t.Fatalf("expected per-day bars instead of a single total:\n%s", got)
+	}
+	if strings.Contains(got, "<h2>2026-01-10</h2>") || strings.Contains(got, "<h2>2026-01-11</h2>") {
+		t.Fatalf("expected range totals without --daily:\n%s", got)
+	}
+}
+
+func TestTerminalWidthPrefersColumnsAndIgnoresNonTerminals(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatalf("unexpected create error: %v", err)
+	}
+	defer f.Close()
+
+	t.Setenv("COLUMNS", "72")
+	if got := terminalWidth(f); got != 72 {
+		t.Fatalf("expected COLUMNS override, got %d", got)
+	}
+	t.Setenv("COLUMNS", "")
+	if got := terminalWidth(f); got != 0 {
+		t.Fatalf("expected 0 for a non-terminal, got %d", got)
+	}
+}
+
+func TestRunWritesChartFormatWithProjectColors(t *testing.T) {
+	client := &fakeTogglClient{
+		timeEntries: []toggl.TimeEntry{
+			{Description: "Design", Start: time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC), Duration: time.Hour, ProjectID: 1, ProjectName: "Alpha"},
+			{Description: "Build", Start: time.Date(2026, 1, 11, 9, 0, 0, 0, time.UTC), Duration: 2 * time.Hour, ProjectID: 1, ProjectName: "Alpha"},
+		},
+		projects: map[int64]toggl.Project{1: {ID: 1, Name: "Alpha", Color: "#2da608"}},
+	}
+	cfg := config.Config{APIToken: "token", WorkspaceID: "999"}
+	var stdout bytes.Buffer
+	deps := runDeps{
+		client: client,
+		stdout: &stdout,
+		now:    func() time.Time { return time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC) },
+		loc:    time.UTC,
+		width:  60,
+	}
+
+	if err := run(context.Background(), Options{From: "2026-01-10", To: "2026-01-11", Format: "chart", Color: true}, cfg, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := stdout.String()
+	for _, want := range []string{
+		"Alpha  \x1b[38;2;45;166;8m" + strings.Repeat("█", 37) + "\x1b[0m     3.00h  100%\n",
+		"Build   " + strings.Repeat("█", 36) + "     2.00h   67%\n",
+		"Total  " + strings.Repeat("▄", 22) + strings.Repeat("█", 21) + "  3.00h\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output:\n%q", want, got)
+		}
+	}
+
+	err := run(context.Background(), Options{Date: "2026-01-10", Color: true}, cfg, deps)
+	if err == nil || !strings.Contains(err.Error(), "--color requires --format chart") {
+		t.Fatalf("expected --color error, got %v", err)
+	}
+}
+
+func TestRunWritesGridStyleFromOutExtension(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "timesheet.csv")
+	client := &fakeTogglClient{
+		timeEntries: []toggl.TimeEntry{
+			{Description: "Design", Start: time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC), Duration: time.Hour, ProjectName: "Alpha"},
+			{Description: "Build", Start: time.Date(2026, 1, 11, 9, 0, 0, 0, time.UTC), Duration: 30 * time.Minute, ProjectName: "Beta"},
+		},
+	}
+	cfg := config.Config{APIToken: "token", WorkspaceID: "999"}
+	deps := runDeps{
+		client: client,
+		stdout: &bytes.Buffer{},
+		now:    func() time.Time { return time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC) },
+		loc:    time.UTC,
+	}
+
+	if err := run(context.Background(), Options{From: "2026-01-10", To: "2026-01-11", Format: "grid", Out: out}, cfg, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	want := "Project,2026-01-10,2026-01-11,Total\nAlpha,1.00,0.00,1.00\nBeta,0.00,0.50,0.50\nTotal,1.00,0.50,1.50\n"
+	if string(data) != want {
+		t.Fatalf("unexpected grid:\n%s", data)
+	}
+
+	for _, tc := range []struct {
+		opts Options
+		want string
+	}{
+		{Options{Date: "2026-01-10", GridRows: "task"}, "--grid-rows and --grid-style require --format grid"},
+		{Options{Date: "2026-01-10", Format: "grid", GridRows: "client"}, "invalid --grid-rows: client"},
+		{Options{Date: "2026-01-10", Format: "grid", GridStyle: "xlsx"}, "invalid --grid-style: xlsx"},
+	} {
+		err := run(context.Background(), tc.opts, cfg, deps)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("expected %q, got %v", tc.want, err)
+		}
+	}
+}
+
+func TestRunWritesICSFormat(t *testing.T) {
+	client := &fakeTogglClient{
+		timeEntries: []toggl.TimeEntry{
+			{ID: 7, Description: "Design", Start: time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC), Duration: time.Hour, ProjectID: 1, Tags: []string{"review"}},
+		},
+		projects: map[int64]toggl.Project{1: {ID: 1, Name: "Alpha"}},
+	}
+	cfg := config.Config{APIToken: "token", WorkspaceID: "999"}
+	var stdout bytes.Buffer
+	deps := runDeps{
+		client: client,
+		stdout: &stdout,
+		now:    func() time.Time { return time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC) },
+		loc:    time.UTC,
+	}
+
+	if err := run(context.Background(), Options{Date: "2026-01-10", Format: "ics"}, cfg, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := stdout.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:7@toggl-daily-summary\r\n",
+		"DTSTART:20260110T090000Z\r\nDTEND:20260110T100000Z\r\n",
+		"CATEGORIES:Alpha,review\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output:\n%q", want, got)
+		}
+	}
+
+	err := run(context.Background(), Options{From: "2026-01-10", To: "2026-01-11", Format: "ics", OutDir: t.TempDir()}, cfg, deps)
+	if err == nil || !strings.Contains(err.Error(), "--format ics cannot be used with --out-dir") {
+		t.Fatalf("expected out-dir error, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/budget"
+	"github.com/yone/toggl-daily-summary/internal/config"
+)
+
+func RunBudget(ctx context.Context, opts BudgetOptions) error {
+	cfg, err := loadConfig(opts.ConfigPath, opts.Profile, opts.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	if opts.DebugConfig {
+		fmt.Fprint(os.Stderr, config.FormatSources(cfg))
+	}
+	if err := config.ResolveToken(&cfg, os.Stderr); err != nil {
+		return err
+	}
+
+	return runBudget(ctx, opts, cfg, runDeps{
+		now:    time.Now,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	})
+}
+
+func runBudget(ctx context.Context, opts BudgetOptions, cfg config.Config, deps runDeps) error {
+	deps, err := prepareDeps(cfg, deps)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Budgets) == 0 {
+		return errors.New("missing budgets: add budgets to config")
+	}
+	if opts.WindowDays < 0 {
+		return fmt.Errorf("invalid --window: %d", opts.WindowDays)
+	}
+	if err := checkOutput(opts.Out, outputMode(false, opts.Force)); err != nil {
+		return err
+	}
+	window := opts.WindowDays
+	if window == 0 {
+		window = 14
+	}
+
+	dr, err := resolveDateRange(Options{Date: opts.Date}, deps.now, deps.loc)
+	if err != nil {
+		return err
+	}
+	asOf := dr.Start
+	monthStart := time.Date(asOf.Year(), asOf.Month(), 1, 0, 0, 0, 0, deps.loc)
+	fetchStart := monthStart
+	if windowStart := dr.End.AddDate(0, 0, -window); windowStart.Before(fetchStart) {
+		fetchStart = windowStart
+	}
+
+	defs := make([]budget.Definition, 0, len(cfg.Budgets))
+	for _, item := range cfg.Budgets {
+		if item.Project == "" {
+			return errors.New("invalid budget: project is required")
+		}
+		if item.TotalHours <= 0 && item.MonthlyHours <= 0 {
+			return fmt.Errorf("invalid budget for %s: set total_hours or monthly_hours", item.Project)
+		}
+		def := budget.Definition{
+			Project:      item.Project,
+			TotalHours:   item.TotalHours,
+			MonthlyHours: item.MonthlyHours,
+			WarnPercent:  item.WarnPercent,
+		}
+		if item.TotalHours > 0 {
+			if item.Start == "" {
+				return fmt.Errorf("invalid budget for %s: start is required with total_hours", item.Project)
+			}
+			start, err := parseDateInLocation(item.Start, deps.loc)
+			if err != nil {
+				return fmt.Errorf("invalid budget start for %s: %w", item.Project, err)
+			}
+			def.Start = start
+			if start.Before(fetchStart) {
+				fetchStart = start
+			}
+		}
+		defs = append(defs, def)
+	}
+
+	timeEntries, err := deps.client.FetchTimeEntries(ctx, fetchStart, dr.End)
+	if err != nil {
+		return err
+	}
+	timeEntries = filterWorkspaces(timeEntries, cfg.Workspaces)
+	projects, _, err := fetchProjects(ctx, deps.client, cfg, timeEntries, false)
+	if err != nil {
+		return err
+	}
+	applyProjects(timeEntries, projects, nil)
+	for i, def := range defs {
+		if id, err := strconv.ParseInt(def.Project, 10, 64); err == nil {
+			if project, ok := projects[id]; ok {
+				defs[i].Project = project.Name
+			}
+		}
+	}
+
+	entries := splitEntriesByDay(buildSummaryEntries(timeEntries, entryOptions{}), deps.loc)
+	reports := make([]budget.Report, 0, len(defs))
+	for _, def := range defs {
+		reports = append(reports, budget.Compute(def, entries, budget.ComputeOptions{
+			AsOf:       asOf,
+			WindowDays: window,
+			Location:   deps.loc,
+		}))
+	}
+
+	for _, warning := range budget.Warnings(reports) {
+		fmt.Fprintf(deps.stderr, "warning: %s\n", warning)
+	}
+
+	return writeOutput(opts.Out, budget.RenderMarkdown(reports), deps.stdout, outputMode(false, opts.Force))
+}
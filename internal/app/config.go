@@ -0,0 +1,265 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/config"
+	"github.com/yone/toggl-daily-summary/internal/toggl"
+)
+
+type ConfigClient interface {
+	FetchMe(ctx context.Context) (toggl.User, error)
+	FetchWorkspaces(ctx context.Context) (map[int64]string, error)
+}
+
+type configDeps struct {
+	stdin     io.Reader
+	stdout    io.Writer
+	stderr    io.Writer
+	newClient func(cfg config.Config) ConfigClient
+}
+
+type workspaceChoice struct {
+	ID   int64
+	Name string
+}
+
+func RunConfigInit(ctx context.Context, opts ConfigOptions) error {
+	return runConfigInit(ctx, opts, newConfigDeps())
+}
+
+func RunConfigShow(opts ConfigOptions) error {
+	return runConfigShow(opts, newConfigDeps())
+}
+
+func RunConfigValidate(opts ConfigOptions) error {
+	return runConfigValidate(opts, newConfigDeps())
+}
+
+func newConfigDeps() configDeps {
+	return configDeps{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		newClient: func(cfg config.Config) ConfigClient {
+			return newTogglClient(cfg, nil)
+		},
+	}
+}
+
+func runConfigInit(ctx context.Context, opts ConfigOptions, deps configDeps) error {
+	path, err := resolveConfigPath(opts.ConfigPath)
+	if err != nil {
+		return err
+	}
+	existing, err := config.Load(path)
+	if err != nil {
+		return fmt.Errorf("cannot read existing config %s: %w", path, err)
+	}
+	if _, err := os.Stat(path); err == nil && opts.Profile == "" && !opts.Force {
+		return fmt.Errorf("config already exists: %s (use --force to replace the connection settings or --profile to add a profile)", path)
+	}
+
+	reader := bufio.NewReader(deps.stdin)
+	ask := func(label, def string) (string, error) {
+		if def != "" {
+			fmt.Fprintf(deps.stdout, "%s [%s]: ", label, def)
+		} else {
+			fmt.Fprintf(deps.stdout, "%s: ", label)
+		}
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		if value := strings.TrimSpace(line); value != "" {
+			return value, nil
+		}
+		return def, nil
+	}
+
+	token, err := ask("Toggl API token (leave empty to use a command)", "")
+	if err != nil {
+		return err
+	}
+	command := ""
+	if token == "" {
+		command, err = ask("Command that prints the token (e.g. pass show toggl)", "")
+		if err != nil {
+			return err
+		}
+		if command == "" {
+			return errors.New("an API token or a token command is required")
+		}
+	}
+
+	probe := config.Config{
+		APIToken:        token,
+		APITokenCommand: command,
+		BaseURL:         existing.BaseURL,
+	}
+	if probe.BaseURL == "" {
+		probe.BaseURL = defaultBaseURL
+	}
+	if err := config.ResolveToken(&probe, deps.stderr); err != nil {
+		return err
+	}
+
+	client := deps.newClient(probe)
+	me, err := client.FetchMe(ctx)
+	if err != nil {
+		return fmt.Errorf("token verification failed: %w", err)
+	}
+	fmt.Fprintf(deps.stdout, "Authenticated as %s <%s>\n", me.Name, me.Email)
+
+	workspaceID := opts.WorkspaceID
+	if workspaceID == "" {
+		found, err := client.FetchWorkspaces(ctx)
+		if err != nil {
+			return err
+		}
+		choices := sortWorkspaces(found)
+		if len(choices) == 0 {
+			return errors.New("no workspaces available for this token")
+		}
+		def := 1
+		fmt.Fprintln(deps.stdout, "Workspaces:")
+		for i, choice := range choices {
+			fmt.Fprintf(deps.stdout, "  %d) %s (%d)\n", i+1, choice.Name, choice.ID)
+			if choice.ID == me.DefaultWorkspaceID {
+				def = i + 1
+			}
+		}
+		answer, err := ask("Workspace", strconv.Itoa(def))
+		if err != nil {
+			return err
+		}
+		index, err := strconv.Atoi(answer)
+		if err != nil || index < 1 || index > len(choices) {
+			return fmt.Errorf("invalid workspace choice: %s", answer)
+		}
+		workspaceID = strconv.FormatInt(choices[index-1].ID, 10)
+	}
+
+	timezone, err := ask("Timezone (leave empty for local time)", "")
+	if err != nil {
+		return err
+	}
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
+	}
+
+	cfg := existing
+	if opts.Profile != "" {
+		if cfg.Profiles == nil {
+			cfg.Profiles = map[string]config.Profile{}
+		}
+		profile := cfg.Profiles[opts.Profile]
+		profile.APIToken = token
+		profile.APITokenCommand = command
+		profile.APITokenFile = ""
+		profile.WorkspaceID = workspaceID
+		profile.Timezone = timezone
+		cfg.Profiles[opts.Profile] = profile
+	} else {
+		cfg.APIToken = token
+		cfg.APITokenCommand = command
+		cfg.APITokenFile = ""
+		cfg.WorkspaceID = workspaceID
+		cfg.Timezone = timezone
+	}
+	if err := config.Save(path, cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(deps.stdout, "Wrote %s\n", path)
+	return nil
+}
+
+func runConfigShow(opts ConfigOptions, deps configDeps) error {
+	cfg, err := loadConfig(opts.ConfigPath, opts.Profile, opts.WorkspaceID)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "config: %s\n", cfg.Path)
+	for _, setting := range config.Settings(cfg) {
+		value := setting.Value
+		if value == "" {
+			value = "-"
+		}
+		fmt.Fprintf(&b, "%s: %s (%s)\n", setting.Key, value, setting.Source)
+	}
+	if cfg.Rates != nil {
+		fmt.Fprintf(&b, "rates: %s (config file)\n", cfg.Rates.Currency)
+	}
+	if cfg.Invoice != nil {
+		fmt.Fprintf(&b, "invoice: %s (config file)\n", cfg.Invoice.Issuer.Name)
+	}
+	if len(cfg.Budgets) > 0 {
+		fmt.Fprintf(&b, "budgets: %d (config file)\n", len(cfg.Budgets))
+	}
+	if cfg.Slack != nil {
+		fmt.Fprintf(&b, "slack: webhook configured (config file)\n")
+	}
+	if cfg.Webhook != nil {
+		fmt.Fprintf(&b, "webhook: configured (config file)\n")
+	}
+	if cfg.Email != nil {
+		fmt.Fprintf(&b, "email: %s via %s (config file)\n", strings.Join(cfg.Email.To, ", "), cfg.Email.Host)
+	}
+	_, err = io.WriteString(deps.stdout, b.String())
+	return err
+}
+
+func runConfigValidate(opts ConfigOptions, deps configDeps) error {
+	path, err := resolveConfigPath(opts.ConfigPath)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	issues := config.Validate(data)
+	if len(issues) == 0 {
+		fmt.Fprintf(deps.stdout, "%s: OK\n", path)
+		return nil
+	}
+	for _, issue := range issues {
+		fmt.Fprintf(deps.stdout, "%s: %s\n", path, issue.Error())
+	}
+	return fmt.Errorf("%d problem(s) found in %s", len(issues), path)
+}
+
+func resolveConfigPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	return config.DefaultPath()
+}
+
+func sortWorkspaces(workspaces map[int64]string) []workspaceChoice {
+	choices := make([]workspaceChoice, 0, len(workspaces))
+	for id, name := range workspaces {
+		choices = append(choices, workspaceChoice{ID: id, Name: name})
+	}
+	sort.Slice(choices, func(i, j int) bool {
+		if choices[i].Name == choices[j].Name {
+			return choices[i].ID < choices[j].ID
+		}
+		return choices[i].Name < choices[j].Name
+	})
+	return choices
+}
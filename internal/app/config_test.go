@@ -0,0 +1,128 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yone/toggl-daily-summary/internal/config"
+	"github.com/yone/toggl-daily-summary/internal/toggl"
+	"github.com/yone/toggl-daily-summary/internal/toggl/toggltest"
+)
+
+func TestRunConfigInitVerifiesTokenAndPicksWorkspace(t *testing.T) {
+	server := toggltest.NewServer()
+	defer server.Close()
+	server.RequireToken("secret-token")
+	server.SetMe(toggl.User{ID: 1, Name: "Alice", Email: "alice@example.com", DefaultWorkspaceID: 888})
+	server.AddWorkspace(999, "Personal")
+	server.AddWorkspace(888, "Company")
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	var stdout bytes.Buffer
+	err := runConfigInit(context.Background(), ConfigOptions{ConfigPath: path}, configDeps{
+		stdin:  strings.NewReader("secret-token\n\nAsia/Tokyo\n"),
+		stdout: &stdout,
+		stderr: &bytes.Buffer{},
+		newClient: func(cfg config.Config) ConfigClient {
+			cfg.BaseURL = server.URL
+			return newTogglClient(cfg, server.Client())
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"Authenticated as Alice <alice@example.com>\n",
+		"  1) Company (888)\n",
+		"  2) Personal (999)\n",
+		"Workspace [1]: ",
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Fatalf("expected %q in output:\n%s", want, stdout.String())
+		}
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("unexpected load error: %v", err)
+	}
+	if cfg.APIToken != "secret-token" || cfg.WorkspaceID != "888" || cfg.Timezone != "Asia/Tokyo" {
+		t.Fatalf("unexpected saved config: %+v", cfg)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected stat error: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected 0600 permissions, got %v", info.Mode().Perm())
+	}
+
+	err = runConfigInit(context.Background(), ConfigOptions{ConfigPath: path}, configDeps{
+		stdin:  strings.NewReader(""),
+		stdout: &bytes.Buffer{},
+	})
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected overwrite refusal, got %v", err)
+	}
+}
+
+func TestRunConfigInitRejectsInvalidToken(t *testing.T) {
+	server := toggltest.NewServer()
+	defer server.Close()
+	server.RequireToken("secret-token")
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := runConfigInit(context.Background(), ConfigOptions{ConfigPath: path}, configDeps{
+		stdin:  strings.NewReader("wrong-token\n"),
+		stdout: &bytes.Buffer{},
+		stderr: &bytes.Buffer{},
+		newClient: func(cfg config.Config) ConfigClient {
+			cfg.BaseURL = server.URL
+			return newTogglClient(cfg, server.Client())
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "token verification failed") {
+		t.Fatalf("expected verification error, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected no config to be written")
+	}
+}
+
+func TestRunConfigShowRedactsToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"api_token": "abcdef123456", "workspace_id": "999", "webhook": {"url": "https://hooks.example.com/in?token=hooksecret"}}`), 0o600); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	t.Setenv("TOGGL_API_TOKEN", "")
+	t.Setenv("TOGGL_WORKSPACE_ID", "")
+	t.Setenv("TOGGL_BASE_URL", "")
+	t.Setenv("TOGGL_TIMEZONE", "")
+	t.Setenv("TOGGL_PROFILE", "")
+
+	var stdout bytes.Buffer
+	if err := runConfigShow(ConfigOptions{ConfigPath: path}, configDeps{stdout: &stdout}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := stdout.String()
+	if strings.Contains(got, "abcdef123456") {
+		t.Fatalf("token leaked:\n%s", got)
+	}
+	if strings.Contains(got, "hooksecret") {
+		t.Fatalf("webhook url leaked:\n%s", got)
+	}
+	for _, want := range []string{
+		"api_token: ********3456 (config file)\n",
+		"webhook: configured (config file)\n",
+		"workspace_id: 999 (config file)\n",
+		"timezone: - (unset)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output:\n%s", want, got)
+		}
+	}
+}
@@ -12,7 +12,7 @@ func TestResolveDateRangeAcceptsSingleDigitMonthDay(t *testing.T) {
 
 	got, err := resolveDateRange(opts, func() time.Time {
 		return time.Date(2026, 1, 10, 0, 0, 0, 0, time.Local)
-	})
+	}, time.Local)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -37,7 +37,7 @@ func TestResolveDateRangeAcceptsZeroPaddedDate(t *testing.T) {
 
 	_, err := resolveDateRange(opts, func() time.Time {
 		return time.Date(2026, 1, 10, 0, 0, 0, 0, time.Local)
-	})
+	}, time.Local)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -51,7 +51,7 @@ func TestResolveDateRangeAcceptsSingleDigitRange(t *testing.T) {
 
 	got, err := resolveDateRange(opts, func() time.Time {
 		return time.Date(2026, 1, 10, 0, 0, 0, 0, time.Local)
-	})
+	}, time.Local)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -76,7 +76,7 @@ func TestResolveDateRangeRejectsMissingYear(t *testing.T) {
 
 	_, err := resolveDateRange(opts, func() time.Time {
 		return time.Date(2026, 1, 10, 0, 0, 0, 0, time.Local)
-	})
+	}, time.Local)
 	if err == nil {
 		t.Fatalf("expected error")
 	}
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/config"
+	"github.com/yone/toggl-daily-summary/internal/toggl"
+)
+
+type DoctorClient interface {
+	FetchMe(ctx context.Context) (toggl.User, error)
+	FetchWorkspace(ctx context.Context, workspaceID string) (string, error)
+	FetchTimeEntryStats(ctx context.Context, start, end time.Time) (toggl.FetchStats, error)
+}
+
+type doctorDeps struct {
+	stdout    io.Writer
+	stderr    io.Writer
+	now       func() time.Time
+	newClient func(cfg config.Config) DoctorClient
+}
+
+type doctorReport struct {
+	w        io.Writer
+	problems int
+}
+
+func (r *doctorReport) ok(format string, args ...any) {
+	fmt.Fprintf(r.w, "[OK]   %s\n", fmt.Sprintf(format, args...))
+}
+
+func (r *doctorReport) warn(hint, format string, args ...any) {
+	fmt.Fprintf(r.w, "[WARN] %s\n", fmt.Sprintf(format, args...))
+	r.hint(hint)
+}
+
+func (r *doctorReport) fail(hint, format string, args ...any) {
+	r.problems++
+	fmt.Fprintf(r.w, "[FAIL] %s\n", fmt.Sprintf(format, args...))
+	r.hint(hint)
+}
+
+func (r *doctorReport) hint(hint string) {
+	if hint != "" {
+		fmt.Fprintf(r.w, "       hint: %s\n", hint)
+	}
+}
+
+func (r *doctorReport) result() error {
+	if r.problems == 0 {
+		return nil
+	}
+	return fmt.Errorf("doctor found %d problem(s)", r.problems)
+}
+
+func RunDoctor(ctx context.Context, opts DoctorOptions) error {
+	return runDoctor(ctx, opts, doctorDeps{
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		now:    time.Now,
+		newClient: func(cfg config.Config) DoctorClient {
+			return newTogglClient(cfg, nil)
+		},
+	})
+}
+
+func runDoctor(ctx context.Context, opts DoctorOptions, deps doctorDeps) error {
+	if deps.now == nil {
+		deps.now = time.Now
+	}
+	report := &doctorReport{w: deps.stdout}
+
+	cfg, err := loadConfig(opts.ConfigPath, opts.Profile, opts.WorkspaceID)
+	if err != nil {
+		report.fail("run `toggl-daily-summary config validate` to locate the problem", "config: %v", err)
+		return report.result()
+	}
+	if data, err := os.ReadFile(cfg.Path); err != nil {
+		report.warn("run `toggl-daily-summary config init`, or set TOGGL_API_TOKEN and TOGGL_WORKSPACE_ID", "config: %s not readable (%v)", cfg.Path, errors.Unwrap(err))
+	} else {
+		issues := config.Validate(data)
+		if len(issues) == 0 {
+			report.ok("config: %s", cfg.Path)
+		}
+		for _, issue := range issues {
+			report.warn("", "config: %s: %s", cfg.Path, issue.Error())
+		}
+	}
+	if cfg.Profile != "" {
+		report.ok("profile: %s (%s)", cfg.Profile, cfg.Sources["profile"])
+	}
+
+	if err := config.ResolveToken(&cfg, deps.stderr); err != nil {
+		report.fail("check that the command or file referenced by the config works on its own", "api_token: %v", err)
+		return report.result()
+	}
+	if cfg.APIToken == "" {
+		report.fail("set TOGGL_API_TOKEN, api_token_command or api_token_file, or run `toggl-daily-summary config init`", "api_token: not set")
+		return report.result()
+	}
+	report.ok("api_token: %s (%s)", config.RedactToken(cfg.APIToken), cfg.Sources["api_token"])
+
+	client := deps.newClient(cfg)
+	me, err := client.FetchMe(ctx)
+	if err != nil {
+		report.fail(apiHint(err, cfg), "/me: %v", err)
+		return report.result()
+	}
+	report.ok("/me: authenticated as %s <%s> via %s", me.Name, me.Email, cfg.BaseURL)
+
+	configured := map[int64]bool{}
+	if cfg.WorkspaceID == "" {
+		hint := "set workspace_id or run `toggl-daily-summary workspaces list`"
+		if me.DefaultWorkspaceID != 0 {
+			hint = fmt.Sprintf("set workspace_id (your default workspace is %d) or run `toggl-daily-summary workspaces list`", me.DefaultWorkspaceID)
+		}
+		report.fail(hint, "workspace: not set")
+	} else {
+		for _, id := range workspaceIDs(cfg, nil) {
+			name, err := client.FetchWorkspace(ctx, id)
+			if err != nil {
+				report.fail(workspaceHint(err, id, cfg), "workspace %s: %v", id, err)
+				continue
+			}
+			report.ok("workspace %s: %s (%s)", id, name, cfg.Sources["workspace_id"])
+			if parsed, err := strconv.ParseInt(id, 10, 64); err == nil {
+				configured[parsed] = true
+			}
+		}
+	}
+
+	loc, err := cfg.Location()
+	if err != nil {
+		report.fail("use an IANA name such as Asia/Tokyo or Europe/Berlin", "timezone: %v", err)
+		return report.result()
+	}
+	source := cfg.Sources["timezone"]
+	if source == "" {
+		source = "system default"
+	}
+	report.ok("timezone: %s, currently %s (%s)", loc.String(), deps.now().In(loc).Format("MST -07:00"), source)
+
+	dr, err := resolveDateRange(Options{Date: opts.Date, From: opts.From, To: opts.To}, deps.now, loc)
+	if err != nil {
+		report.fail("dates use YYYY-M-D, e.g. --date 2026-1-10", "range: %v", err)
+		return report.result()
+	}
+	report.ok("range: %s .. %s (end exclusive)", dr.Start.Format(time.RFC3339), dr.End.Format(time.RFC3339))
+
+	stats, err := client.FetchTimeEntryStats(ctx, dr.Start, dr.End)
+	if err != nil {
+		report.fail(apiHint(err, cfg), "time entries: %v", err)
+		return report.result()
+	}
+	dropped := stats.Running + stats.InvalidStart
+	report.ok("time entries: %d returned, %d usable, %d zero duration kept as 0.00h lines, %d dropped (running %d, unparsable start %d)",
+		stats.Returned, stats.Usable, stats.ZeroDuration, dropped, stats.Running, stats.InvalidStart)
+	if stats.Returned == 0 {
+		report.warn("check the date and timezone above; only the token owner's entries are returned (use --team for the whole workspace)", "time entries: none in range")
+	}
+	if stats.Running > 0 {
+		report.warn("running timers are left out until they are stopped", "time entries: %d running", stats.Running)
+	}
+	if stats.ZeroDuration > 0 {
+		report.warn("zero-length entries are kept as 0.00h lines; remove them in Toggl if they are mistakes", "time entries: %d with zero duration", stats.ZeroDuration)
+	}
+	if stats.InvalidStart > 0 {
+		report.fail("the summary aborts on these entries; fix their start time in Toggl", "time entries: %d with an unparsable start", stats.InvalidStart)
+	}
+	if len(cfg.Workspaces) > 0 {
+		var others []int64
+		for id := range stats.Workspaces {
+			if id != 0 && !configured[id] {
+				others = append(others, id)
+			}
+		}
+		sort.Slice(others, func(i, j int) bool { return others[i] < others[j] })
+		for _, id := range others {
+			report.warn(fmt.Sprintf("add %d to workspaces in config to include them", id), "time entries: %d in workspace %d are filtered out", stats.Workspaces[id], id)
+		}
+	}
+
+	return report.result()
+}
+
+func apiHint(err error, cfg config.Config) string {
+	var apiErr *toggl.APIError
+	if !errors.As(err, &apiErr) {
+		return fmt.Sprintf("cannot reach %s; check the network, proxy settings and base_url", cfg.BaseURL)
+	}
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "the API token was rejected; copy it again from your Toggl profile page"
+	case http.StatusNotFound:
+		return fmt.Sprintf("base_url %s does not look like the Toggl API v9 endpoint", cfg.BaseURL)
+	case http.StatusPaymentRequired, http.StatusTooManyRequests:
+		return "the API rate limit or plan quota was hit; wait and try again"
+	default:
+		return ""
+	}
+}
+
+func workspaceHint(err error, workspaceID string, cfg config.Config) string {
+	var apiErr *toggl.APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusForbidden || apiErr.StatusCode == http.StatusNotFound) {
+		return fmt.Sprintf("this token cannot access workspace %s; run `toggl-daily-summary workspaces list` for valid IDs", workspaceID)
+	}
+	return apiHint(err, cfg)
+}
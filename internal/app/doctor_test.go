@@ -0,0 +1,126 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/config"
+	"github.com/yone/toggl-daily-summary/internal/toggl"
+	"github.com/yone/toggl-daily-summary/internal/toggl/toggltest"
+)
+
+func writeDoctorConfig(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	t.Setenv("TOGGL_API_TOKEN", "")
+	t.Setenv("TOGGL_WORKSPACE_ID", "")
+	t.Setenv("TOGGL_BASE_URL", "")
+	t.Setenv("TOGGL_TIMEZONE", "")
+	t.Setenv("TOGGL_PROFILE", "")
+	return path
+}
+
+func doctorTestDeps(server *toggltest.Server, stdout *bytes.Buffer) doctorDeps {
+	return doctorDeps{
+		stdout: stdout,
+		stderr: &bytes.Buffer{},
+		now: func() time.Time {
+			return time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+		},
+		newClient: func(cfg config.Config) DoctorClient {
+			cfg.BaseURL = server.URL
+			return newTogglClient(cfg, server.Client())
+		},
+	}
+}
+
+func TestRunDoctorReportsDroppedEntries(t *testing.T) {
+	server := toggltest.NewServer()
+	defer server.Close()
+	server.RequireToken("secret-token")
+	server.SetMe(toggl.User{ID: 1, Name: "Alice", Email: "alice@example.com", DefaultWorkspaceID: 999})
+	server.AddWorkspace(999, "Personal")
+	server.AddTimeEntries(
+		toggl.TimeEntry{ID: 1, WorkspaceID: 999, Description: "Design", Start: time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC), Duration: time.Hour},
+		toggl.TimeEntry{ID: 2, WorkspaceID: 888, Description: "Side", Start: time.Date(2026, 1, 10, 10, 0, 0, 0, time.UTC), Duration: time.Hour},
+		toggl.TimeEntry{ID: 3, WorkspaceID: 999, Description: "Now", Start: time.Date(2026, 1, 10, 11, 0, 0, 0, time.UTC), Duration: -time.Second},
+	)
+
+	path := writeDoctorConfig(t, `{"api_token": "secret-token", "workspaces": ["999"], "timezone": "UTC"}`)
+	var stdout bytes.Buffer
+	err := runDoctor(context.Background(), DoctorOptions{ConfigPath: path, Date: "2026-1-10"}, doctorTestDeps(server, &stdout))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, stdout.String())
+	}
+
+	got := stdout.String()
+	for _, want := range []string{
+		"[OK]   config: " + path + "\n",
+		"[OK]   api_token: ********oken (config file)\n",
+		"[OK]   /me: authenticated as Alice <alice@example.com>",
+		"[OK]   workspace 999: Personal (config file)\n",
+		"[OK]   timezone: UTC, currently UTC +00:00 (config file)\n",
+		"[OK]   range: 2026-01-10T00:00:00Z .. 2026-01-11T00:00:00Z (end exclusive)\n",
+		"[OK]   time entries: 3 returned, 2 usable, 0 zero duration kept as 0.00h lines, 1 dropped (running 1, unparsable start 0)\n",
+		"[WARN] time entries: 1 running\n",
+		"[WARN] time entries: 1 in workspace 888 are filtered out\n",
+		"       hint: add 888 to workspaces in config to include them\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output:\n%s", want, got)
+		}
+	}
+}
+
+func TestRunDoctorFailsOnRejectedToken(t *testing.T) {
+	server := toggltest.NewServer()
+	defer server.Close()
+	server.RequireToken("secret-token")
+
+	path := writeDoctorConfig(t, `{"api_token": "wrong-token", "workspace_id": "999"}`)
+	var stdout bytes.Buffer
+	err := runDoctor(context.Background(), DoctorOptions{ConfigPath: path}, doctorTestDeps(server, &stdout))
+	if err == nil || err.Error() != "doctor found 1 problem(s)" {
+		t.Fatalf("expected one problem, got %v", err)
+	}
+
+	got := stdout.String()
+	if !strings.Contains(got, "[FAIL] /me: ") || !strings.Contains(got, "hint: the API token was rejected") {
+		t.Fatalf("expected token failure with hint:\n%s", got)
+	}
+	if strings.Contains(got, "time entries") {
+		t.Fatalf("expected doctor to stop after /me failed:\n%s", got)
+	}
+}
+
+func TestRunDoctorSuggestsDefaultWorkspace(t *testing.T) {
+	server := toggltest.NewServer()
+	defer server.Close()
+	server.SetMe(toggl.User{ID: 1, Name: "Alice", Email: "alice@example.com", DefaultWorkspaceID: 999})
+
+	path := writeDoctorConfig(t, `{"api_token": "secret-token", "timezone": "UTC"}`)
+	var stdout bytes.Buffer
+	err := runDoctor(context.Background(), DoctorOptions{ConfigPath: path, Date: "2026-1-10"}, doctorTestDeps(server, &stdout))
+	if err == nil {
+		t.Fatalf("expected missing workspace to fail:\n%s", stdout.String())
+	}
+
+	got := stdout.String()
+	for _, want := range []string{
+		"[FAIL] workspace: not set\n",
+		"hint: set workspace_id (your default workspace is 999)",
+		"[WARN] time entries: none in range\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output:\n%s", want, got)
+		}
+	}
+}
@@ -1,16 +1,28 @@
 package app
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/yone/toggl-daily-summary/internal/config"
 	"github.com/yone/toggl-daily-summary/internal/summary"
 	"github.com/yone/toggl-daily-summary/internal/toggl"
+	"golang.org/x/term"
 )
 
-func buildSummaryEntries(entries []toggl.TimeEntry) []summary.Entry {
+type entryOptions struct {
+	rates          *config.Rates
+	workspaces     bool
+	workspaceNames map[int64]string
+}
+
+func buildSummaryEntries(entries []toggl.TimeEntry, opts entryOptions) []summary.Entry {
 	out := make([]summary.Entry, 0, len(entries))
 	for _, entry := range entries {
 		project := strings.TrimSpace(entry.ProjectName)
@@ -21,12 +33,82 @@ func buildSummaryEntries(entries []toggl.TimeEntry) []summary.Entry {
 		if strings.TrimSpace(task) == "" {
 			task = "No Description"
 		}
-		out = append(out, summary.Entry{
+		item := summary.Entry{
+			User:     entry.UserName,
 			Project:  project,
 			Task:     task,
 			Start:    entry.Start,
 			Duration: entry.Duration,
-		})
+			Billable: entry.Billable,
+		}
+		if opts.rates != nil {
+			item.Rate = resolveRate(*opts.rates, entry)
+		}
+		if opts.workspaces {
+			item.Workspace = workspaceName(entry.WorkspaceID, opts.workspaceNames)
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+func resolveRate(rates config.Rates, entry toggl.TimeEntry) float64 {
+	if entry.ProjectID != 0 {
+		if rate, ok := rates.Projects[strconv.FormatInt(entry.ProjectID, 10)]; ok {
+			return rate
+		}
+	}
+	if name := strings.TrimSpace(entry.ProjectName); name != "" {
+		if rate, ok := rates.Projects[name]; ok {
+			return rate
+		}
+	}
+	if name := strings.TrimSpace(entry.ClientName); name != "" {
+		if rate, ok := rates.Clients[name]; ok {
+			return rate
+		}
+	}
+	if entry.WorkspaceID != 0 {
+		if rate, ok := rates.Workspaces[strconv.FormatInt(entry.WorkspaceID, 10)]; ok {
+			return rate
+		}
+	}
+	return rates.Default
+}
+
+func workspaceName(id int64, names map[int64]string) string {
+	if id == 0 {
+		return ""
+	}
+	if name := strings.TrimSpace(names[id]); name != "" {
+		return name
+	}
+	return strconv.FormatInt(id, 10)
+}
+
+func filterWorkspaces(entries []toggl.TimeEntry, workspaceIDs []string) []toggl.TimeEntry {
+	if len(workspaceIDs) == 0 {
+		return entries
+	}
+	allowed := map[string]bool{}
+	for _, id := range workspaceIDs {
+		allowed[strings.TrimSpace(id)] = true
+	}
+	out := make([]toggl.TimeEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.WorkspaceID == 0 || allowed[strconv.FormatInt(entry.WorkspaceID, 10)] {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+func filterBillable(entries []toggl.TimeEntry) []toggl.TimeEntry {
+	out := make([]toggl.TimeEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Billable {
+			out = append(out, entry)
+		}
 	}
 	return out
 }
@@ -56,10 +138,14 @@ func splitEntriesByDay(entries []summary.Entry, loc *time.Location) []summary.En
 			segmentDuration := segmentEnd.Sub(current)
 			if segmentDuration > 0 {
 				out = append(out, summary.Entry{
-					Project:  entry.Project,
-					Task:     entry.Task,
-					Start:    current,
-					Duration: segmentDuration,
+					Workspace: entry.Workspace,
+					User:      entry.User,
+					Project:   entry.Project,
+					Task:      entry.Task,
+					Start:     current,
+					Duration:  segmentDuration,
+					Billable:  entry.Billable,
+					Rate:      entry.Rate,
 				})
 			}
 			current = segmentEnd
@@ -69,10 +155,100 @@ func splitEntriesByDay(entries []summary.Entry, loc *time.Location) []summary.En
 	return out
 }
 
-func writeOutput(outPath, content string, stdout io.Writer) error {
+type writeMode int
+
+const (
+	writeCreate writeMode = iota
+	writeOverwrite
+	writeAppend
+)
+
+func terminalWidth(f *os.File) int {
+	if width, err := strconv.Atoi(strings.TrimSpace(os.Getenv("COLUMNS"))); err == nil && width > 0 {
+		return width
+	}
+	fd := int(f.Fd())
+	if !term.IsTerminal(fd) {
+		return 0
+	}
+	width, _, err := term.GetSize(fd)
+	if err != nil || width <= 0 {
+		return 0
+	}
+	return width
+}
+
+func outputMode(appendOutput, force bool) writeMode {
+	switch {
+	case appendOutput:
+		return writeAppend
+	case force:
+		return writeOverwrite
+	default:
+		return writeCreate
+	}
+}
+
+func checkOutput(outPath string, mode writeMode) error {
+	if outPath == "" || mode != writeCreate {
+		return nil
+	}
+	if _, err := os.Stat(outPath); err == nil {
+		return fmt.Errorf("%s already exists (use --force to overwrite or --append to add to it)", outPath)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func writeOutput(outPath, content string, stdout io.Writer, mode writeMode) error {
 	if outPath == "" {
 		_, err := io.WriteString(stdout, content)
 		return err
 	}
-	return os.WriteFile(outPath, []byte(content), 0o644)
+	if err := checkOutput(outPath, mode); err != nil {
+		return err
+	}
+
+	data := []byte(content)
+	perm := os.FileMode(0o644)
+	if info, err := os.Stat(outPath); err == nil {
+		perm = info.Mode().Perm()
+		if mode == writeAppend {
+			existing, err := os.ReadFile(outPath)
+			if err != nil {
+				return err
+			}
+			data = append(existing, data...)
+		}
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(outPath), "."+filepath.Base(outPath)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, outPath); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
 }
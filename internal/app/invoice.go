@@ -0,0 +1,179 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/config"
+	"github.com/yone/toggl-daily-summary/internal/invoice"
+	"github.com/yone/toggl-daily-summary/internal/summary"
+	"github.com/yone/toggl-daily-summary/internal/toggl"
+)
+
+func RunInvoice(ctx context.Context, opts InvoiceOptions) error {
+	cfg, err := loadConfig(opts.ConfigPath, opts.Profile, opts.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	if opts.DebugConfig {
+		fmt.Fprint(os.Stderr, config.FormatSources(cfg))
+	}
+	if err := config.ResolveToken(&cfg, os.Stderr); err != nil {
+		return err
+	}
+
+	return runInvoice(ctx, opts, cfg, runDeps{
+		now:    time.Now,
+		stdout: os.Stdout,
+	})
+}
+
+func runInvoice(ctx context.Context, opts InvoiceOptions, cfg config.Config, deps runDeps) error {
+	deps, err := prepareDeps(cfg, deps)
+	if err != nil {
+		return err
+	}
+
+	dr, err := resolveDateRange(Options{Date: opts.Date, From: opts.From, To: opts.To}, deps.now, deps.loc)
+	if err != nil {
+		return err
+	}
+
+	format, err := parseInvoiceFormat(opts.Format)
+	if err != nil {
+		return err
+	}
+	if err := checkOutput(opts.Out, outputMode(false, opts.Force)); err != nil {
+		return err
+	}
+	if cfg.Rates == nil || strings.TrimSpace(cfg.Rates.Currency) == "" {
+		return errors.New("missing rates: set rates.currency in config to create an invoice")
+	}
+	invoiceCfg := config.Invoice{}
+	if cfg.Invoice != nil {
+		invoiceCfg = *cfg.Invoice
+	}
+
+	timeEntries, err := deps.client.FetchTimeEntries(ctx, dr.Start, dr.End)
+	if err != nil {
+		return err
+	}
+	timeEntries = filterWorkspaces(timeEntries, cfg.Workspaces)
+	if opts.BillableOnly {
+		timeEntries = filterBillable(timeEntries)
+	}
+	projects, clients, err := fetchProjects(ctx, deps.client, cfg, timeEntries, true)
+	if err != nil {
+		return err
+	}
+	applyProjects(timeEntries, projects, clients)
+	if opts.Client != "" {
+		timeEntries = filterClient(timeEntries, opts.Client)
+	}
+
+	entries := buildSummaryEntries(timeEntries, entryOptions{rates: cfg.Rates})
+	buckets := summary.Aggregate(entries, summary.AggregateOptions{
+		Location:               deps.loc,
+		SeparateTasksByProject: true,
+	})
+
+	lines, err := invoice.BuildLines(buckets, invoice.BuildOptions{
+		GroupBy:         opts.GroupBy,
+		RoundingMinutes: invoiceCfg.Rounding.Minutes,
+		RoundingMode:    invoiceCfg.Rounding.Mode,
+	})
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return errors.New("no time entries to invoice in the selected range")
+	}
+
+	number := opts.Number
+	var seq *invoice.Sequence
+	if number == "" {
+		seq, err = invoice.OpenSequence(invoiceCfg.SequenceFile, invoiceCfg.NumberFormat)
+		if err != nil {
+			return err
+		}
+		number = seq.Number()
+	}
+
+	issued := deps.now().In(deps.loc)
+	issueDate := time.Date(issued.Year(), issued.Month(), issued.Day(), 0, 0, 0, 0, deps.loc)
+	inv := invoice.Invoice{
+		Number:      number,
+		IssueDate:   issueDate,
+		PeriodStart: dr.Start,
+		PeriodEnd:   dr.End.AddDate(0, 0, -1),
+		Issuer:      toInvoiceParty(invoiceCfg.Issuer),
+		Recipient:   resolveRecipient(invoiceCfg, opts.Client),
+		Currency:    strings.TrimSpace(cfg.Rates.Currency),
+		Lines:       lines,
+		TaxRate:     invoiceCfg.TaxRate,
+	}
+	if invoiceCfg.DueDays > 0 {
+		inv.DueDate = issueDate.AddDate(0, 0, invoiceCfg.DueDays)
+	}
+	invoice.Totals(&inv)
+
+	output := invoice.RenderMarkdown(inv)
+	if format == "html" {
+		output, err = invoice.RenderHTML(inv)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writeOutput(opts.Out, output, deps.stdout, outputMode(false, opts.Force)); err != nil {
+		return err
+	}
+	if seq != nil {
+		return seq.Commit()
+	}
+	return nil
+}
+
+func parseInvoiceFormat(format string) (string, error) {
+	format = strings.TrimSpace(strings.ToLower(format))
+	switch format {
+	case "", "markdown", "md":
+		return "markdown", nil
+	case "html":
+		return "html", nil
+	default:
+		return "", fmt.Errorf("invalid --format: %s", format)
+	}
+}
+
+func filterClient(entries []toggl.TimeEntry, client string) []toggl.TimeEntry {
+	out := make([]toggl.TimeEntry, 0, len(entries))
+	for _, entry := range entries {
+		if strings.EqualFold(strings.TrimSpace(entry.ClientName), strings.TrimSpace(client)) {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+func resolveRecipient(cfg config.Invoice, client string) invoice.Party {
+	if party, ok := cfg.Clients[client]; ok {
+		if party.Name == "" {
+			party.Name = client
+		}
+		return toInvoiceParty(party)
+	}
+	return invoice.Party{Name: client}
+}
+
+func toInvoiceParty(party config.Party) invoice.Party {
+	return invoice.Party{
+		Name:    party.Name,
+		Address: party.Address,
+		Email:   party.Email,
+	}
+}
@@ -0,0 +1,192 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/config"
+)
+
+type projectListItem struct {
+	ID          int64  `json:"id"`
+	WorkspaceID int64  `json:"workspace_id"`
+	Name        string `json:"name"`
+	Client      string `json:"client,omitempty"`
+	Active      bool   `json:"active"`
+	Color       string `json:"color,omitempty"`
+}
+
+type workspaceListItem struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func RunProjectsList(ctx context.Context, opts ListOptions) error {
+	cfg, err := loadListConfig(opts)
+	if err != nil {
+		return err
+	}
+	return runProjectsList(ctx, opts, cfg, runDeps{
+		now:    time.Now,
+		stdout: os.Stdout,
+	})
+}
+
+func RunWorkspacesList(ctx context.Context, opts ListOptions) error {
+	cfg, err := loadListConfig(opts)
+	if err != nil {
+		return err
+	}
+	return runWorkspacesList(ctx, opts, cfg, runDeps{
+		now:    time.Now,
+		stdout: os.Stdout,
+	})
+}
+
+func loadListConfig(opts ListOptions) (config.Config, error) {
+	cfg, err := loadConfig(opts.ConfigPath, opts.Profile, opts.WorkspaceID)
+	if err != nil {
+		return config.Config{}, err
+	}
+	if err := config.ResolveToken(&cfg, os.Stderr); err != nil {
+		return config.Config{}, err
+	}
+	return cfg, nil
+}
+
+func runProjectsList(ctx context.Context, opts ListOptions, cfg config.Config, deps runDeps) error {
+	deps, err := prepareDeps(cfg, deps)
+	if err != nil {
+		return err
+	}
+	format, err := parseListFormat(opts.Format)
+	if err != nil {
+		return err
+	}
+
+	projects, clients, err := fetchProjects(ctx, deps.client, cfg, nil, true)
+	if err != nil {
+		return err
+	}
+
+	items := make([]projectListItem, 0, len(projects))
+	for _, project := range projects {
+		item := projectListItem{
+			ID:          project.ID,
+			WorkspaceID: project.WorkspaceID,
+			Name:        project.Name,
+			Client:      clients[project.ClientID],
+			Active:      project.Active,
+			Color:       project.Color,
+		}
+		if !matchesSearch(opts.Search, item.Name, item.Client) {
+			continue
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if strings.EqualFold(items[i].Name, items[j].Name) {
+			return items[i].ID < items[j].ID
+		}
+		return strings.ToLower(items[i].Name) < strings.ToLower(items[j].Name)
+	})
+
+	if format == "json" {
+		return writeListJSON(deps.stdout, items)
+	}
+	w := tabwriter.NewWriter(deps.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tCLIENT\tACTIVE\tCOLOR")
+	for _, item := range items {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%s\n", item.ID, item.Name, dashIfEmpty(item.Client), item.Active, dashIfEmpty(item.Color))
+	}
+	return w.Flush()
+}
+
+func runWorkspacesList(ctx context.Context, opts ListOptions, cfg config.Config, deps runDeps) error {
+	format, err := parseListFormat(opts.Format)
+	if err != nil {
+		return err
+	}
+	if deps.client == nil {
+		if cfg.APIToken == "" {
+			return errMissingAPIToken
+		}
+		deps.client = newTogglClient(cfg, nil)
+	}
+	if deps.stdout == nil {
+		deps.stdout = os.Stdout
+	}
+
+	workspaceClient, ok := deps.client.(WorkspaceClient)
+	if !ok {
+		return errors.New("listing workspaces requires the Toggl API")
+	}
+	workspaces, err := workspaceClient.FetchWorkspaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	items := make([]workspaceListItem, 0, len(workspaces))
+	for _, choice := range sortWorkspaces(workspaces) {
+		if !matchesSearch(opts.Search, choice.Name) {
+			continue
+		}
+		items = append(items, workspaceListItem{ID: choice.ID, Name: choice.Name})
+	}
+
+	if format == "json" {
+		return writeListJSON(deps.stdout, items)
+	}
+	w := tabwriter.NewWriter(deps.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME")
+	for _, item := range items {
+		fmt.Fprintf(w, "%d\t%s\n", item.ID, item.Name)
+	}
+	return w.Flush()
+}
+
+func parseListFormat(format string) (string, error) {
+	format = strings.TrimSpace(strings.ToLower(format))
+	switch format {
+	case "", "table":
+		return "table", nil
+	case "json":
+		return "json", nil
+	default:
+		return "", fmt.Errorf("invalid --format: %s", format)
+	}
+}
+
+func matchesSearch(search string, values ...string) bool {
+	search = strings.ToLower(strings.TrimSpace(search))
+	if search == "" {
+		return true
+	}
+	for _, value := range values {
+		if strings.Contains(strings.ToLower(value), search) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeListJSON(w io.Writer, value any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(value)
+}
+
+func dashIfEmpty(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/yone/toggl-daily-summary/internal/config"
+	"github.com/yone/toggl-daily-summary/internal/toggl"
+	"github.com/yone/toggl-daily-summary/internal/toggl/toggltest"
+)
+
+func TestRunProjectsListTable(t *testing.T) {
+	server := toggltest.NewServer()
+	defer server.Close()
+	server.AddProjects("999",
+		toggl.Project{ID: 222, WorkspaceID: 999, Name: "beta", ClientID: 5, Active: true, Color: "#06aaf5"},
+		toggl.Project{ID: 111, WorkspaceID: 999, Name: "Alpha", Active: false},
+	)
+	server.AddClient("999", 5, "Acme")
+
+	cfg := config.Config{APIToken: "token", WorkspaceID: "999", BaseURL: server.URL}
+	var buf bytes.Buffer
+	err := runProjectsList(context.Background(), ListOptions{}, cfg, runDeps{
+		client: toggl.NewClient(cfg.BaseURL, cfg.APIToken, server.Client()),
+		stdout: &buf,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "" +
+		"ID   NAME   CLIENT  ACTIVE  COLOR\n" +
+		"111  Alpha  -       false   -\n" +
+		"222  beta   Acme    true    #06aaf5\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n--- got ---\n%s\n--- want ---\n%s", buf.String(), want)
+	}
+}
+
+func TestRunProjectsListSearchJSON(t *testing.T) {
+	server := toggltest.NewServer()
+	defer server.Close()
+	server.AddProjects("999",
+		toggl.Project{ID: 222, WorkspaceID: 999, Name: "Website", ClientID: 5, Active: true},
+		toggl.Project{ID: 111, WorkspaceID: 999, Name: "Internal", Active: true},
+	)
+	server.AddClient("999", 5, "Acme")
+
+	cfg := config.Config{APIToken: "token", WorkspaceID: "999", BaseURL: server.URL}
+	var buf bytes.Buffer
+	err := runProjectsList(context.Background(), ListOptions{Format: "json", Search: "acme"}, cfg, runDeps{
+		client: toggl.NewClient(cfg.BaseURL, cfg.APIToken, server.Client()),
+		stdout: &buf,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []projectListItem
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(got) != 1 || got[0].ID != 222 || got[0].Client != "Acme" {
+		t.Fatalf("unexpected projects: %+v", got)
+	}
+}
+
+func TestRunWorkspacesListDoesNotRequireWorkspaceID(t *testing.T) {
+	server := toggltest.NewServer()
+	defer server.Close()
+	server.AddWorkspace(999, "Personal")
+	server.AddWorkspace(888, "Company")
+
+	cfg := config.Config{APIToken: "token", BaseURL: server.URL}
+	var buf bytes.Buffer
+	err := runWorkspacesList(context.Background(), ListOptions{Search: "comp"}, cfg, runDeps{
+		client: toggl.NewClient(cfg.BaseURL, cfg.APIToken, server.Client()),
+		stdout: &buf,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "" +
+		"ID   NAME\n" +
+		"888  Company\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n--- got ---\n%s\n--- want ---\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/config"
+	"github.com/yone/toggl-daily-summary/internal/note"
+)
+
+func writeNotes(opts Options, cfg config.Config, deps runDeps, report summaryReport) error {
+	loc := report.formatOpts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	days, err := splitReportByDay(report)
+	if err != nil {
+		return err
+	}
+	for _, day := range days {
+		date, err := time.ParseInLocation(note.DefaultDateLayout, day.date, loc)
+		if err != nil {
+			return fmt.Errorf("invalid bucket date %q: %w", day.date, err)
+		}
+		path, err := config.ExpandHome(note.Expand(cfg.Notes.Path, date, cfg.Notes.DateFormat))
+		if err != nil {
+			return err
+		}
+
+		content, err := readNote(path, cfg.Notes, date)
+		if err != nil {
+			return err
+		}
+		updated := note.Upsert(content, cfg.Notes.Heading, day.markdown)
+
+		if opts.DryRun {
+			fmt.Fprintf(deps.stdout, "==> %s\n%s", path, updated)
+			continue
+		}
+		if updated == content {
+			fmt.Fprintf(deps.stdout, "Unchanged %s\n", path)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := writeOutput(path, updated, deps.stdout, writeOverwrite); err != nil {
+			return err
+		}
+		fmt.Fprintf(deps.stdout, "Updated %s\n", path)
+	}
+	return nil
+}
+
+func readNote(path string, notes *config.Notes, date time.Time) (string, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return string(data), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if notes.Template == "" {
+		return "", nil
+	}
+	templatePath, err := config.ExpandHome(notes.Template)
+	if err != nil {
+		return "", err
+	}
+	data, err = os.ReadFile(templatePath)
+	if err != nil {
+		return "", fmt.Errorf("read note template: %w", err)
+	}
+	return note.Expand(string(data), date, notes.DateFormat), nil
+}
@@ -7,7 +7,84 @@ type Options struct {
 	Daily                bool
 	SeparateTaskProjects bool
 	Out                  string
+	OutDir               string
+	OutName              string
+	Append               bool
+	Force                bool
 	ConfigPath           string
+	Profile              string
+	DebugConfig          bool
 	WorkspaceID          string
 	Format               string
+	Color                bool
+	GridRows             string
+	GridStyle            string
+	Top                  int
+	TaskDelimiter        string
+	BillableOnly         bool
+	ShowBillable         bool
+	Earnings             bool
+	NoCache              bool
+	Refresh              bool
+	Input                string
+	Record               string
+	Replay               string
+	Team                 bool
+	Users                []string
+	GroupByWorkspace     bool
+	Post                 string
+	DryRun               bool
+	AppendToNote         bool
+}
+
+type InvoiceOptions struct {
+	Date         string
+	From         string
+	To           string
+	Client       string
+	GroupBy      string
+	Format       string
+	Number       string
+	BillableOnly bool
+	Out          string
+	Force        bool
+	ConfigPath   string
+	Profile      string
+	DebugConfig  bool
+	WorkspaceID  string
+}
+
+type BudgetOptions struct {
+	Date        string
+	WindowDays  int
+	Out         string
+	Force       bool
+	ConfigPath  string
+	Profile     string
+	DebugConfig bool
+	WorkspaceID string
+}
+
+type ConfigOptions struct {
+	ConfigPath  string
+	Profile     string
+	WorkspaceID string
+	Force       bool
+}
+
+type ListOptions struct {
+	Format      string
+	Search      string
+	ConfigPath  string
+	Profile     string
+	WorkspaceID string
+}
+
+type DoctorOptions struct {
+	Date        string
+	From        string
+	To          string
+	ConfigPath  string
+	Profile     string
+	WorkspaceID string
 }
@@ -0,0 +1,253 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/config"
+	"github.com/yone/toggl-daily-summary/internal/email"
+	"github.com/yone/toggl-daily-summary/internal/slack"
+	"github.com/yone/toggl-daily-summary/internal/summary"
+	"github.com/yone/toggl-daily-summary/internal/webhook"
+)
+
+type summaryReport struct {
+	output     string
+	markdown   string
+	buckets    []summary.Bucket
+	formatOpts summary.FormatOptions
+}
+
+type daySummary struct {
+	date     string
+	output   string
+	markdown string
+}
+
+func parsePost(opts Options, cfg config.Config) (string, error) {
+	post := strings.ToLower(strings.TrimSpace(opts.Post))
+	if opts.OutDir != "" {
+		if opts.Out != "" {
+			return "", errors.New("use either --out or --out-dir, not both")
+		}
+		if !strings.Contains(outName(opts), "{{date}}") {
+			return "", fmt.Errorf("--out-name must contain {{date}}: %s", opts.OutName)
+		}
+	}
+	if err := checkOutput(opts.Out, outputMode(opts.Append, opts.Force)); err != nil {
+		return "", err
+	}
+	if opts.AppendToNote {
+		if post != "" || opts.Out != "" || opts.OutDir != "" {
+			return "", errors.New("use --append-to-note without --out, --out-dir or --post")
+		}
+		if cfg.Notes == nil || strings.TrimSpace(cfg.Notes.Path) == "" {
+			return "", errors.New("missing notes: set notes.path in config to use --append-to-note")
+		}
+		return "", nil
+	}
+	switch post {
+	case "":
+		if opts.DryRun {
+			return "", errors.New("--dry-run requires --post or --append-to-note")
+		}
+	case "slack":
+		if cfg.Slack == nil || strings.TrimSpace(cfg.Slack.WebhookURL) == "" {
+			return "", errors.New("missing slack webhook: set slack.webhook_url in config to use --post slack")
+		}
+	case "webhook":
+		if cfg.Webhook == nil || strings.TrimSpace(cfg.Webhook.URL) == "" {
+			return "", errors.New("missing webhook: set webhook.url in config to use --post webhook")
+		}
+	case "email":
+		if cfg.Email == nil || strings.TrimSpace(cfg.Email.Host) == "" || strings.TrimSpace(cfg.Email.From) == "" || len(cfg.Email.To) == 0 {
+			return "", errors.New("missing email settings: set email.host, email.from and email.to in config to use --post email")
+		}
+	default:
+		return "", fmt.Errorf("invalid --post: %s", opts.Post)
+	}
+	return post, nil
+}
+
+func deliverOutput(ctx context.Context, post string, opts Options, cfg config.Config, deps runDeps, report summaryReport) error {
+	if opts.AppendToNote {
+		return writeNotes(opts, cfg, deps, report)
+	}
+	switch {
+	case opts.OutDir != "":
+		if err := writeOutDir(opts, deps, report); err != nil {
+			return err
+		}
+	case post == "" || opts.Out != "":
+		if err := writeOutput(opts.Out, report.output, deps.stdout, outputMode(opts.Append, opts.Force)); err != nil {
+			return err
+		}
+	}
+
+	switch post {
+	case "slack":
+		messages := slack.Messages(report.markdown)
+		if opts.DryRun {
+			for _, message := range messages {
+				if err := writeListJSON(deps.stdout, message); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return slack.NewClient(cfg.Slack.WebhookURL, nil).Post(ctx, messages)
+	case "webhook":
+		payload := webhook.NewPayload(report.buckets, webhook.PayloadOptions{
+			RangeStart: report.formatOpts.RangeStart,
+			RangeEnd:   report.formatOpts.RangeEnd,
+			Location:   report.formatOpts.Location,
+			Currency:   report.formatOpts.Currency,
+			Markdown:   report.markdown,
+		})
+		body, err := webhook.Render(cfg.Webhook.BodyTemplate, payload)
+		if err != nil {
+			return err
+		}
+		if opts.DryRun {
+			_, err := fmt.Fprintf(deps.stdout, "%s\n", body)
+			return err
+		}
+		contentType := cfg.Webhook.ContentType
+		if contentType == "" && cfg.Webhook.BodyTemplate == "" {
+			contentType = "application/json"
+		}
+		return webhook.NewClient(webhook.Options{
+			URL:             cfg.Webhook.URL,
+			Headers:         cfg.Webhook.Headers,
+			Secret:          cfg.Webhook.Secret,
+			SignatureHeader: cfg.Webhook.SignatureHeader,
+			ContentType:     contentType,
+			Retries:         cfg.Webhook.Retries,
+		}, nil).Send(ctx, body)
+	case "email":
+		data, err := buildEmail(cfg, deps, report)
+		if err != nil {
+			return err
+		}
+		if opts.DryRun {
+			_, err := deps.stdout.Write(data)
+			return err
+		}
+		return email.Send(ctx, email.Options{
+			Host:     cfg.Email.Host,
+			Port:     cfg.Email.Port,
+			StartTLS: cfg.Email.StartTLS,
+			Username: cfg.Email.Username,
+			Password: cfg.Email.Password,
+			From:     cfg.Email.From,
+			To:       cfg.Email.To,
+		}, data)
+	default:
+		return nil
+	}
+}
+
+func buildEmail(cfg config.Config, deps runDeps, report summaryReport) ([]byte, error) {
+	html, err := summary.FormatHTML(report.buckets, report.formatOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := report.formatOpts.Location
+	start := report.formatOpts.RangeStart.In(loc).Format("2006-01-02")
+	end := report.formatOpts.RangeEnd.In(loc).AddDate(0, 0, -1).Format("2006-01-02")
+	rangeText := start
+	if end > start {
+		rangeText = start + ".." + end
+	}
+	var total time.Duration
+	for _, bucket := range report.buckets {
+		total += bucket.Total
+	}
+	subject, err := email.RenderSubject(cfg.Email.Subject, email.SubjectData{
+		Start: start,
+		End:   end,
+		Range: rangeText,
+		Total: summary.FormatHours(total) + "h",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return email.Build(email.Message{
+		From:    cfg.Email.From,
+		To:      cfg.Email.To,
+		Subject: subject,
+		Text:    report.markdown,
+		HTML:    html,
+		Date:    deps.now(),
+	})
+}
+
+func writeOutDir(opts Options, deps runDeps, report summaryReport) error {
+	mode := outputMode(opts.Append, opts.Force)
+	days, err := splitReportByDay(report)
+	if err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(days))
+	for _, day := range days {
+		path := filepath.Join(opts.OutDir, strings.ReplaceAll(outName(opts), "{{date}}", day.date))
+		if err := checkOutput(path, mode); err != nil {
+			return err
+		}
+		paths = append(paths, path)
+	}
+	if err := os.MkdirAll(opts.OutDir, 0o755); err != nil {
+		return err
+	}
+	for i, day := range days {
+		if err := writeOutput(paths[i], day.output, deps.stdout, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func outName(opts Options) string {
+	if opts.OutName == "" {
+		return "{{date}}.md"
+	}
+	return opts.OutName
+}
+
+func splitReportByDay(report summaryReport) ([]daySummary, error) {
+	dayOpts := report.formatOpts
+	dayOpts.Daily = false
+	loc := dayOpts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	days := make([]daySummary, 0, len(report.buckets))
+	for _, bucket := range report.buckets {
+		date, err := time.ParseInLocation("2006-01-02", bucket.Date, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket date %q: %w", bucket.Date, err)
+		}
+		day := bucket
+		day.Date = ""
+		bucketOpts := dayOpts
+		bucketOpts.RangeStart = date
+		bucketOpts.RangeEnd = date.AddDate(0, 0, 1)
+		output, err := renderOutput([]summary.Bucket{day}, bucketOpts)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, daySummary{
+			date:     bucket.Date,
+			output:   output,
+			markdown: summary.FormatMarkdown([]summary.Bucket{day}, bucketOpts),
+		})
+	}
+	return days, nil
+}
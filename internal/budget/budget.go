@@ -0,0 +1,186 @@
+package budget
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/summary"
+)
+
+const (
+	dateLayout         = "2006-01-02"
+	monthLayout        = "2006-01"
+	defaultWarnPercent = 80
+)
+
+type Definition struct {
+	Project      string
+	TotalHours   float64
+	MonthlyHours float64
+	Start        time.Time
+	WarnPercent  float64
+}
+
+type Usage struct {
+	Label      string
+	Budget     time.Duration
+	Used       time.Duration
+	Remaining  time.Duration
+	Percent    float64
+	Exhaustion time.Time
+	Warn       bool
+	Exceeded   bool
+}
+
+type Report struct {
+	Project     string
+	WarnPercent float64
+	WindowDays  int
+	DailyRate   time.Duration
+	Total       *Usage
+	Monthly     *Usage
+}
+
+type ComputeOptions struct {
+	AsOf       time.Time
+	WindowDays int
+	Location   *time.Location
+}
+
+func Compute(def Definition, entries []summary.Entry, opts ComputeOptions) Report {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	window := opts.WindowDays
+	if window <= 0 {
+		window = 14
+	}
+	warnPercent := def.WarnPercent
+	if warnPercent <= 0 {
+		warnPercent = defaultWarnPercent
+	}
+
+	asOf := opts.AsOf.In(loc)
+	dayEnd := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+	windowStart := dayEnd.AddDate(0, 0, -window)
+	monthStart := time.Date(asOf.Year(), asOf.Month(), 1, 0, 0, 0, 0, loc)
+
+	var totalUsed, monthUsed, windowUsed time.Duration
+	for _, entry := range entries {
+		if entry.Project != def.Project {
+			continue
+		}
+		start := entry.Start.In(loc)
+		if !start.Before(dayEnd) {
+			continue
+		}
+		if !def.Start.IsZero() && !start.Before(def.Start) {
+			totalUsed += entry.Duration
+		}
+		if !start.Before(monthStart) {
+			monthUsed += entry.Duration
+		}
+		if !start.Before(windowStart) {
+			windowUsed += entry.Duration
+		}
+	}
+
+	report := Report{
+		Project:     def.Project,
+		WarnPercent: warnPercent,
+		WindowDays:  window,
+		DailyRate:   windowUsed / time.Duration(window),
+	}
+	if def.TotalHours > 0 {
+		label := "合計"
+		if !def.Start.IsZero() {
+			label = fmt.Sprintf("合計 (%s〜)", def.Start.In(loc).Format(dateLayout))
+		}
+		usage := newUsage(label, def.TotalHours, totalUsed, report.DailyRate, dayEnd, warnPercent)
+		report.Total = &usage
+	}
+	if def.MonthlyHours > 0 {
+		usage := newUsage(asOf.Format(monthLayout), def.MonthlyHours, monthUsed, report.DailyRate, dayEnd, warnPercent)
+		report.Monthly = &usage
+	}
+	return report
+}
+
+func newUsage(label string, hours float64, used, dailyRate time.Duration, dayEnd time.Time, warnPercent float64) Usage {
+	budget := time.Duration(hours * float64(time.Hour))
+	usage := Usage{
+		Label:     label,
+		Budget:    budget,
+		Used:      used,
+		Remaining: budget - used,
+		Percent:   float64(used) / float64(budget) * 100,
+	}
+	usage.Warn = usage.Percent >= warnPercent
+	usage.Exceeded = used > budget
+	if usage.Remaining > 0 && dailyRate > 0 {
+		days := int(math.Ceil(float64(usage.Remaining) / float64(dailyRate)))
+		usage.Exhaustion = dayEnd.AddDate(0, 0, days-1)
+	}
+	return usage
+}
+
+func Warnings(reports []Report) []string {
+	var out []string
+	for _, report := range reports {
+		for _, usage := range []*Usage{report.Total, report.Monthly} {
+			if usage == nil {
+				continue
+			}
+			switch {
+			case usage.Exceeded:
+				out = append(out, fmt.Sprintf("%s %s: budget exceeded (%.1f%%)", report.Project, usage.Label, usage.Percent))
+			case usage.Warn:
+				out = append(out, fmt.Sprintf("%s %s: %.1f%% of budget used (threshold %g%%)", report.Project, usage.Label, usage.Percent, report.WarnPercent))
+			}
+		}
+	}
+	return out
+}
+
+func RenderMarkdown(reports []Report) string {
+	var b strings.Builder
+	for i, report := range reports {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## %s\n", report.Project)
+		b.WriteString("\n")
+		b.WriteString("| 区分 | 予算 | 消化 | 残り | 消化率 | 枯渇予測 |\n")
+		b.WriteString("| --- | ---: | ---: | ---: | ---: | --- |\n")
+		for _, usage := range []*Usage{report.Total, report.Monthly} {
+			if usage == nil {
+				continue
+			}
+			exhaustion := "-"
+			if !usage.Exhaustion.IsZero() {
+				exhaustion = usage.Exhaustion.Format(dateLayout)
+			}
+			marker := ""
+			if usage.Exceeded {
+				marker = " ⛔"
+			} else if usage.Warn {
+				marker = " ⚠"
+			}
+			fmt.Fprintf(&b, "| %s | %sh | %sh | %sh | %.1f%%%s | %s |\n",
+				usage.Label,
+				summary.FormatHours(usage.Budget),
+				summary.FormatHours(usage.Used),
+				summary.FormatHours(usage.Remaining),
+				usage.Percent,
+				marker,
+				exhaustion,
+			)
+		}
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "- 直近 %d 日の平均: %sh/日\n", report.WindowDays, summary.FormatHours(report.DailyRate))
+	}
+	return b.String()
+}
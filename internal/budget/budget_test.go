@@ -0,0 +1,92 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/summary"
+)
+
+func TestComputeProjectsExhaustion(t *testing.T) {
+	loc := time.UTC
+	var entries []summary.Entry
+	for day := 1; day <= 10; day++ {
+		entries = append(entries, summary.Entry{
+			Project:  "Alpha",
+			Task:     "Build",
+			Start:    time.Date(2026, 1, day, 9, 0, 0, 0, loc),
+			Duration: 2 * time.Hour,
+		})
+	}
+	entries = append(entries, summary.Entry{
+		Project:  "Beta",
+		Task:     "Other",
+		Start:    time.Date(2026, 1, 5, 9, 0, 0, 0, loc),
+		Duration: 5 * time.Hour,
+	})
+
+	report := Compute(Definition{
+		Project:      "Alpha",
+		TotalHours:   40,
+		MonthlyHours: 24,
+		Start:        time.Date(2026, 1, 1, 0, 0, 0, 0, loc),
+	}, entries, ComputeOptions{
+		AsOf:       time.Date(2026, 1, 10, 0, 0, 0, 0, loc),
+		WindowDays: 5,
+		Location:   loc,
+	})
+
+	if report.DailyRate != 2*time.Hour {
+		t.Fatalf("unexpected daily rate: %s", report.DailyRate)
+	}
+	if report.Total == nil || report.Total.Used != 20*time.Hour || report.Total.Remaining != 20*time.Hour {
+		t.Fatalf("unexpected total usage: %+v", report.Total)
+	}
+	wantExhaustion := time.Date(2026, 1, 20, 0, 0, 0, 0, loc)
+	if !report.Total.Exhaustion.Equal(wantExhaustion) {
+		t.Fatalf("unexpected exhaustion: %s", report.Total.Exhaustion)
+	}
+	if report.Total.Warn {
+		t.Fatalf("total should not warn at 50%%")
+	}
+	if report.Monthly == nil || !report.Monthly.Warn || report.Monthly.Exceeded {
+		t.Fatalf("expected monthly warning: %+v", report.Monthly)
+	}
+
+	warnings := Warnings([]Report{report})
+	if len(warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %v", warnings)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	report := Report{
+		Project:     "Alpha",
+		WarnPercent: 80,
+		WindowDays:  14,
+		DailyRate:   time.Hour,
+		Monthly: &Usage{
+			Label:     "2026-01",
+			Budget:    10 * time.Hour,
+			Used:      11 * time.Hour,
+			Remaining: -time.Hour,
+			Percent:   110,
+			Warn:      true,
+			Exceeded:  true,
+		},
+	}
+
+	got := RenderMarkdown([]Report{report})
+	want := "" +
+		"## Alpha\n" +
+		"\n" +
+		"| 区分 | 予算 | 消化 | 残り | 消化率 | 枯渇予測 |\n" +
+		"| --- | ---: | ---: | ---: | ---: | --- |\n" +
+		"| 2026-01 | 10.00h | 11.00h | -1.00h | 110.0% ⛔ | - |\n" +
+		"\n" +
+		"- 直近 14 日の平均: 1.00h/日\n"
+
+	if got != want {
+		t.Fatalf("unexpected markdown:\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
@@ -0,0 +1,414 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/toggl"
+)
+
+const (
+	dateLayout         = "2006-01-02"
+	defaultRecentDays  = 7
+	defaultMetadataTTL = 24 * time.Hour
+	settleMargin       = time.Hour
+)
+
+type Source interface {
+	FetchTimeEntries(ctx context.Context, start, end time.Time) ([]toggl.TimeEntry, error)
+	FetchTimeEntriesSince(ctx context.Context, since time.Time) (toggl.SyncResult, error)
+	FetchProjects(ctx context.Context, workspaceID string) (map[int64]toggl.Project, error)
+	FetchClients(ctx context.Context, workspaceID string) (map[int64]string, error)
+	FetchWorkspaces(ctx context.Context) (map[int64]string, error)
+}
+
+type Options struct {
+	Dir         string
+	Location    *time.Location
+	Now         func() time.Time
+	Refresh     bool
+	RecentDays  int
+	MetadataTTL time.Duration
+	Warnings    io.Writer
+}
+
+type Client struct {
+	source      Source
+	dir         string
+	loc         *time.Location
+	now         func() time.Time
+	refresh     bool
+	recentDays  int
+	metadataTTL time.Duration
+	warnings    io.Writer
+}
+
+type dayFile struct {
+	FetchedAt time.Time         `json:"fetched_at"`
+	Entries   []toggl.TimeEntry `json:"entries"`
+}
+
+type projectsFile struct {
+	FetchedAt time.Time               `json:"fetched_at"`
+	Projects  map[int64]toggl.Project `json:"projects"`
+}
+
+type clientsFile struct {
+	FetchedAt time.Time        `json:"fetched_at"`
+	Clients   map[int64]string `json:"clients"`
+}
+
+type workspacesFile struct {
+	FetchedAt  time.Time        `json:"fetched_at"`
+	Workspaces map[int64]string `json:"workspaces"`
+}
+
+func DefaultDir(token, workspaceID string) (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(token))
+	return filepath.Join(base, "toggl-daily-summary", hex.EncodeToString(sum[:])[:16], workspaceID), nil
+}
+
+func New(source Source, opts Options) *Client {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	recentDays := opts.RecentDays
+	if recentDays <= 0 {
+		recentDays = defaultRecentDays
+	}
+	ttl := opts.MetadataTTL
+	if ttl <= 0 {
+		ttl = defaultMetadataTTL
+	}
+	warnings := opts.Warnings
+	if warnings == nil {
+		warnings = os.Stderr
+	}
+	return &Client{
+		source:      source,
+		dir:         opts.Dir,
+		loc:         loc,
+		now:         now,
+		refresh:     opts.Refresh,
+		recentDays:  recentDays,
+		metadataTTL: ttl,
+		warnings:    warnings,
+	}
+}
+
+func (c *Client) FetchTimeEntries(ctx context.Context, start, end time.Time) ([]toggl.TimeEntry, error) {
+	days, ok := c.daysIn(start, end)
+	if !ok {
+		return c.source.FetchTimeEntries(ctx, start, end)
+	}
+
+	today := c.dayStart(c.now())
+	cutoff := today.AddDate(0, 0, -(c.recentDays - 1))
+
+	loaded := map[string]*dayFile{}
+	var missing []time.Time
+	var recent []time.Time
+	for _, day := range days {
+		if c.refresh {
+			missing = append(missing, day)
+			continue
+		}
+		file, err := c.readDay(day)
+		if err != nil {
+			return nil, err
+		}
+		if file == nil {
+			missing = append(missing, day)
+			continue
+		}
+		settled := file.FetchedAt.After(day.AddDate(0, 0, 1).Add(settleMargin))
+		if day.Before(cutoff) && !settled {
+			missing = append(missing, day)
+			continue
+		}
+		loaded[day.Format(dateLayout)] = file
+		if !day.Before(cutoff) {
+			recent = append(recent, day)
+		}
+	}
+
+	if len(recent) > 0 {
+		if err := c.syncRecent(ctx, recent, loaded); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, span := range contiguousSpans(missing) {
+		fetchedAt := c.now()
+		entries, err := c.source.FetchTimeEntries(ctx, span[0], span[len(span)-1].AddDate(0, 0, 1))
+		if err != nil {
+			return nil, err
+		}
+		files := map[string]*dayFile{}
+		for _, day := range span {
+			files[day.Format(dateLayout)] = &dayFile{FetchedAt: fetchedAt, Entries: []toggl.TimeEntry{}}
+		}
+		for _, entry := range entries {
+			key := c.dayStart(entry.Start).Format(dateLayout)
+			if file, ok := files[key]; ok {
+				file.Entries = append(file.Entries, entry)
+			}
+		}
+		for _, day := range span {
+			key := day.Format(dateLayout)
+			if err := c.writeDay(day, files[key]); err != nil {
+				return nil, err
+			}
+			loaded[key] = files[key]
+		}
+	}
+
+	var out []toggl.TimeEntry
+	for _, day := range days {
+		file := loaded[day.Format(dateLayout)]
+		if file == nil {
+			continue
+		}
+		for _, entry := range file.Entries {
+			if entry.Start.Before(start) || !entry.Start.Before(end) {
+				continue
+			}
+			out = append(out, entry)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Start.Before(out[j].Start)
+	})
+	return out, nil
+}
+
+func (c *Client) syncRecent(ctx context.Context, days []time.Time, loaded map[string]*dayFile) error {
+	since := loaded[days[0].Format(dateLayout)].FetchedAt
+	for _, day := range days[1:] {
+		if fetchedAt := loaded[day.Format(dateLayout)].FetchedAt; fetchedAt.Before(since) {
+			since = fetchedAt
+		}
+	}
+
+	fetchedAt := c.now()
+	result, err := c.source.FetchTimeEntriesSince(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	removed := map[int64]bool{}
+	for _, id := range result.Removed {
+		removed[id] = true
+	}
+	for _, entry := range result.Entries {
+		removed[entry.ID] = true
+	}
+
+	for _, day := range days {
+		key := day.Format(dateLayout)
+		file := loaded[key]
+		kept := make([]toggl.TimeEntry, 0, len(file.Entries))
+		for _, entry := range file.Entries {
+			if !removed[entry.ID] {
+				kept = append(kept, entry)
+			}
+		}
+		file.Entries = kept
+		file.FetchedAt = fetchedAt
+	}
+	for _, entry := range result.Entries {
+		if file, ok := loaded[c.dayStart(entry.Start).Format(dateLayout)]; ok {
+			file.Entries = append(file.Entries, entry)
+		}
+	}
+	for _, day := range days {
+		if err := c.writeDay(day, loaded[day.Format(dateLayout)]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) FetchProjects(ctx context.Context, workspaceID string) (map[int64]toggl.Project, error) {
+	path := c.metadataPath("projects", workspaceID)
+	var cached projectsFile
+	if ok, err := c.readFresh(path, &cached, func() time.Time { return cached.FetchedAt }); err != nil {
+		return nil, err
+	} else if ok {
+		return cached.Projects, nil
+	}
+
+	projects, err := c.source.FetchProjects(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeJSON(path, projectsFile{FetchedAt: c.now(), Projects: projects}); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (c *Client) FetchClients(ctx context.Context, workspaceID string) (map[int64]string, error) {
+	path := c.metadataPath("clients", workspaceID)
+	var cached clientsFile
+	if ok, err := c.readFresh(path, &cached, func() time.Time { return cached.FetchedAt }); err != nil {
+		return nil, err
+	} else if ok {
+		return cached.Clients, nil
+	}
+
+	clients, err := c.source.FetchClients(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeJSON(path, clientsFile{FetchedAt: c.now(), Clients: clients}); err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+func (c *Client) FetchWorkspaces(ctx context.Context) (map[int64]string, error) {
+	path := filepath.Join(c.dir, "workspaces.json")
+	var cached workspacesFile
+	if ok, err := c.readFresh(path, &cached, func() time.Time { return cached.FetchedAt }); err != nil {
+		return nil, err
+	} else if ok {
+		return cached.Workspaces, nil
+	}
+
+	workspaces, err := c.source.FetchWorkspaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeJSON(path, workspacesFile{FetchedAt: c.now(), Workspaces: workspaces}); err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+func (c *Client) metadataPath(kind, workspaceID string) string {
+	return filepath.Join(c.dir, kind+"-"+workspaceID+".json")
+}
+
+func (c *Client) readFresh(path string, out any, fetchedAt func() time.Time) (bool, error) {
+	if c.refresh {
+		return false, nil
+	}
+	ok, err := c.readJSON(path, out)
+	if err != nil || !ok {
+		return false, err
+	}
+	return c.now().Sub(fetchedAt()) < c.metadataTTL, nil
+}
+
+func (c *Client) daysIn(start, end time.Time) ([]time.Time, bool) {
+	start = start.In(c.loc)
+	end = end.In(c.loc)
+	if !start.Equal(c.dayStart(start)) || !end.Equal(c.dayStart(end)) || !start.Before(end) {
+		return nil, false
+	}
+	var days []time.Time
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days, true
+}
+
+func (c *Client) dayStart(t time.Time) time.Time {
+	t = t.In(c.loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.loc)
+}
+
+func (c *Client) dayPath(day time.Time) string {
+	return filepath.Join(c.dir, "entries", day.Format(dateLayout)+".json")
+}
+
+func (c *Client) readDay(day time.Time) (*dayFile, error) {
+	var file dayFile
+	ok, err := c.readJSON(c.dayPath(day), &file)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (c *Client) writeDay(day time.Time, file *dayFile) error {
+	return writeJSON(c.dayPath(day), file)
+}
+
+func contiguousSpans(days []time.Time) [][]time.Time {
+	var spans [][]time.Time
+	for _, day := range days {
+		if n := len(spans); n > 0 {
+			last := spans[n-1][len(spans[n-1])-1]
+			if last.AddDate(0, 0, 1).Equal(day) {
+				spans[n-1] = append(spans[n-1], day)
+				continue
+			}
+		}
+		spans = append(spans, []time.Time{day})
+	}
+	return spans
+}
+
+func (c *Client) readJSON(path string, out any) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		fmt.Fprintf(c.warnings, "warning: ignoring corrupt cache file %s: %v\n", path, err)
+		return false, nil
+	}
+	return true, nil
+}
+
+func writeJSON(path string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/toggl"
+)
+
+type fakeSource struct {
+	entries     []toggl.TimeEntry
+	sync        toggl.SyncResult
+	rangeCalls  int
+	sinceCalls  int
+	lastSince   time.Time
+	projectCall int
+}
+
+func (f *fakeSource) FetchTimeEntries(_ context.Context, start, end time.Time) ([]toggl.TimeEntry, error) {
+	f.rangeCalls++
+	var out []toggl.TimeEntry
+	for _, entry := range f.entries {
+		if !entry.Start.Before(start) && entry.Start.Before(end) {
+			out = append(out, entry)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSource) FetchTimeEntriesSince(_ context.Context, since time.Time) (toggl.SyncResult, error) {
+	f.sinceCalls++
+	f.lastSince = since
+	return f.sync, nil
+}
+
+func (f *fakeSource) FetchProjects(_ context.Context, _ string) (map[int64]toggl.Project, error) {
+	f.projectCall++
+	return map[int64]toggl.Project{111: {ID: 111, Name: "Alpha"}}, nil
+}
+
+func (f *fakeSource) FetchClients(_ context.Context, _ string) (map[int64]string, error) {
+	return map[int64]string{}, nil
+}
+
+func (f *fakeSource) FetchWorkspaces(_ context.Context) (map[int64]string, error) {
+	return map[int64]string{999: "Personal"}, nil
+}
+
+func TestFetchTimeEntriesCachesOlderDays(t *testing.T) {
+	source := &fakeSource{
+		entries: []toggl.TimeEntry{
+			{ID: 1, Description: "Design", Start: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC), Duration: time.Hour},
+			{ID: 2, Description: "Build", Start: time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC), Duration: time.Hour},
+		},
+	}
+	client := New(source, Options{
+		Dir:      t.TempDir(),
+		Location: time.UTC,
+		Now: func() time.Time {
+			return time.Date(2026, 1, 20, 12, 0, 0, 0, time.UTC)
+		},
+	})
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		entries, err := client.FetchTimeEntries(context.Background(), start, end)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(entries) != 2 || entries[0].ID != 1 || entries[1].ID != 2 {
+			t.Fatalf("unexpected entries: %+v", entries)
+		}
+	}
+	if source.rangeCalls != 1 || source.sinceCalls != 0 {
+		t.Fatalf("expected a single range fetch, got range=%d since=%d", source.rangeCalls, source.sinceCalls)
+	}
+}
+
+func TestFetchTimeEntriesRefetchesDaysCachedBeforeTheyEnded(t *testing.T) {
+	source := &fakeSource{
+		entries: []toggl.TimeEntry{
+			{ID: 1, Description: "Design", Start: time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC), Duration: time.Hour},
+		},
+	}
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	client := New(source, Options{
+		Dir:      t.TempDir(),
+		Location: time.UTC,
+		Now:      func() time.Time { return now },
+	})
+
+	start := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)
+	if _, err := client.FetchTimeEntries(context.Background(), start, end); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	source.entries = append(source.entries, toggl.TimeEntry{ID: 2, Description: "Build", Start: time.Date(2026, 1, 10, 15, 0, 0, 0, time.UTC), Duration: time.Hour})
+	now = time.Date(2026, 1, 25, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		entries, err := client.FetchTimeEntries(context.Background(), start, end)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("expected the day to be refetched, got %+v", entries)
+		}
+	}
+	if source.rangeCalls != 2 {
+		t.Fatalf("expected one refetch and then a settled cache, got %d range calls", source.rangeCalls)
+	}
+}
+
+func TestFetchTimeEntriesWarnsAboutCorruptDayFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "entries", "2026-01-01.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	source := &fakeSource{
+		entries: []toggl.TimeEntry{
+			{ID: 1, Description: "Design", Start: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC), Duration: time.Hour},
+		},
+	}
+	var warnings bytes.Buffer
+	client := New(source, Options{
+		Dir:      dir,
+		Location: time.UTC,
+		Now: func() time.Time {
+			return time.Date(2026, 1, 20, 12, 0, 0, 0, time.UTC)
+		},
+		Warnings: &warnings,
+	})
+
+	entries, err := client.FetchTimeEntries(context.Background(), time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 || source.rangeCalls != 1 {
+		t.Fatalf("expected corrupt day to be refetched, got %+v (calls=%d)", entries, source.rangeCalls)
+	}
+	if !strings.Contains(warnings.String(), "corrupt cache file "+path) {
+		t.Fatalf("expected warning with path, got %q", warnings.String())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "Design") {
+		t.Fatalf("expected day file to be rewritten, got %q (%v)", data, err)
+	}
+}
+
+func TestFetchTimeEntriesSyncsRecentDays(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	source := &fakeSource{
+		entries: []toggl.TimeEntry{
+			{ID: 1, Description: "Design", Start: time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC), Duration: time.Hour},
+			{ID: 2, Description: "Build", Start: time.Date(2026, 1, 10, 10, 0, 0, 0, time.UTC), Duration: time.Hour},
+		},
+	}
+	client := New(source, Options{
+		Dir:      t.TempDir(),
+		Location: time.UTC,
+		Now: func() time.Time {
+			return now
+		},
+	})
+
+	start := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)
+	if _, err := client.FetchTimeEntries(context.Background(), start, end); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	source.sync = toggl.SyncResult{
+		Entries: []toggl.TimeEntry{
+			{ID: 2, Description: "Build", Start: time.Date(2026, 1, 10, 10, 0, 0, 0, time.UTC), Duration: 2 * time.Hour},
+			{ID: 3, Description: "Review", Start: time.Date(2026, 1, 10, 13, 0, 0, 0, time.UTC), Duration: time.Hour},
+		},
+		Removed: []int64{1},
+	}
+	fetchedAt := now
+	now = now.Add(time.Hour)
+
+	entries, err := client.FetchTimeEntries(context.Background(), start, end)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if source.rangeCalls != 1 || source.sinceCalls != 1 {
+		t.Fatalf("unexpected calls: range=%d since=%d", source.rangeCalls, source.sinceCalls)
+	}
+	if !source.lastSince.Equal(fetchedAt) {
+		t.Fatalf("unexpected since: %s", source.lastSince)
+	}
+	if len(entries) != 2 || entries[0].ID != 2 || entries[0].Duration != 2*time.Hour || entries[1].ID != 3 {
+		t.Fatalf("unexpected entries after sync: %+v", entries)
+	}
+}
+
+func TestFetchProjectsRespectsRefresh(t *testing.T) {
+	dir := t.TempDir()
+	source := &fakeSource{}
+	now := func() time.Time { return time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC) }
+
+	cached := New(source, Options{Dir: dir, Now: now})
+	for i := 0; i < 2; i++ {
+		if _, err := cached.FetchProjects(context.Background(), "999"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if source.projectCall != 1 {
+		t.Fatalf("expected cached projects, got %d calls", source.projectCall)
+	}
+
+	refreshed := New(source, Options{Dir: dir, Now: now, Refresh: true})
+	projects, err := refreshed.FetchProjects(context.Background(), "999")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if source.projectCall != 2 || projects[111].Name != "Alpha" {
+		t.Fatalf("expected refresh to bypass cache, got %d calls", source.projectCall)
+	}
+}
+
+func TestFetchProjectsKeepsWorkspacesApart(t *testing.T) {
+	dir := t.TempDir()
+	source := &fakeSource{}
+	now := func() time.Time { return time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC) }
+
+	client := New(source, Options{Dir: dir, Now: now})
+	for _, workspaceID := range []string{"999", "888", "999"} {
+		if _, err := client.FetchProjects(context.Background(), workspaceID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if source.projectCall != 2 {
+		t.Fatalf("expected one fetch per workspace, got %d calls", source.projectCall)
+	}
+}
+
+func TestDefaultDirSeparatesTokens(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	first, err := DefaultDir("token-a", "999")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := DefaultDir("token-b", "999")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first == second || filepath.Base(first) != "999" || strings.Contains(first, "token-a") {
+		t.Fatalf("expected per-token cache dirs, got %s and %s", first, second)
+	}
+}
+
+func TestWriteJSONLeavesNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "projects-999.json")
+	if err := os.WriteFile(path+".tmp", []byte("keep me"), 0o600); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	if err := writeJSON(path, map[string]int{"a": 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(path + ".tmp"); string(data) != "keep me" {
+		t.Fatalf("expected %s.tmp to be left alone, got %q", path, data)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected readdir error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected only the cache file and the unrelated tmp file, got %v", entries)
+	}
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"github.com/yone/toggl-daily-summary/internal/app"
+)
+
+func newBudgetCmd() *cobra.Command {
+	opts := &app.BudgetOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "budget",
+		Short: "Report project budget consumption and projected exhaustion",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			return app.RunBudget(ctx, *opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Date, "date", "", "Report as of date in YYYY-M-D (default: today, local)")
+	cmd.Flags().IntVar(&opts.WindowDays, "window", 14, "Days of recent activity used for the projection")
+	cmd.Flags().StringVar(&opts.Out, "out", "", "Write output to file (default: stdout)")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Overwrite --out if it already exists")
+	cmd.Flags().StringVar(&opts.ConfigPath, "config", "", "Config file path (default: ~/.config/toggl-daily-summary/config.json)")
+	cmd.Flags().StringVar(&opts.Profile, "profile", "", "Config profile name (overrides TOGGL_PROFILE and default_profile)")
+	cmd.Flags().StringVar(&opts.WorkspaceID, "workspace", "", "Workspace ID (overrides config/env)")
+	cmd.Flags().BoolVar(&opts.DebugConfig, "debug-config", false, "Print where each config value came from to stderr")
+
+	return cmd
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"github.com/yone/toggl-daily-summary/internal/app"
+)
+
+func newConfigCmd() *cobra.Command {
+	opts := &app.ConfigOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Create, inspect and validate the config file",
+	}
+	cmd.PersistentFlags().StringVar(&opts.ConfigPath, "config", "", "Config file path (default: ~/.config/toggl-daily-summary/config.json)")
+
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create the config interactively and verify the API token",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			return app.RunConfigInit(ctx, *opts)
+		},
+	}
+	initCmd.Flags().StringVar(&opts.Profile, "profile", "", "Write the settings into this profile instead of the top level")
+	initCmd.Flags().StringVar(&opts.WorkspaceID, "workspace", "", "Workspace ID (skips the workspace picker)")
+	initCmd.Flags().BoolVar(&opts.Force, "force", false, "Replace the connection settings of an existing config")
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the effective config with the token redacted and the source of each value",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return app.RunConfigShow(*opts)
+		},
+	}
+	showCmd.Flags().StringVar(&opts.Profile, "profile", "", "Config profile name (overrides TOGGL_PROFILE and default_profile)")
+	showCmd.Flags().StringVar(&opts.WorkspaceID, "workspace", "", "Workspace ID (overrides config/env)")
+
+	validateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check the config file for unknown fields and invalid values",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return app.RunConfigValidate(*opts)
+		},
+	}
+
+	cmd.AddCommand(initCmd)
+	cmd.AddCommand(showCmd)
+	cmd.AddCommand(validateCmd)
+
+	return cmd
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"github.com/yone/toggl-daily-summary/internal/app"
+)
+
+func newDoctorCmd() *cobra.Command {
+	opts := &app.DoctorOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check config, API access and the entries seen for a date range",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			return app.RunDoctor(ctx, *opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Date, "date", "", "Target date in YYYY-M-D (default: today, local)")
+	cmd.Flags().StringVar(&opts.From, "from", "", "Start date in YYYY-M-D")
+	cmd.Flags().StringVar(&opts.To, "to", "", "End date in YYYY-M-D")
+	cmd.Flags().StringVar(&opts.ConfigPath, "config", "", "Config file path (default: ~/.config/toggl-daily-summary/config.json)")
+	cmd.Flags().StringVar(&opts.Profile, "profile", "", "Config profile name (overrides TOGGL_PROFILE and default_profile)")
+	cmd.Flags().StringVar(&opts.WorkspaceID, "workspace", "", "Workspace ID (overrides config/env)")
+
+	return cmd
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"github.com/yone/toggl-daily-summary/internal/app"
+)
+
+func newInvoiceCmd() *cobra.Command {
+	opts := &app.InvoiceOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "invoice",
+		Short: "Create an invoice for a client from tracked time",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			return app.RunInvoice(ctx, *opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Date, "date", "", "Target date in YYYY-M-D (default: today, local)")
+	cmd.Flags().StringVar(&opts.From, "from", "", "Start date in YYYY-M-D")
+	cmd.Flags().StringVar(&opts.To, "to", "", "End date in YYYY-M-D")
+	cmd.Flags().StringVar(&opts.Client, "client", "", "Client name to invoice (default: all entries)")
+	cmd.Flags().StringVar(&opts.GroupBy, "group-by", "project", "Line items per project or task")
+	cmd.Flags().StringVar(&opts.Format, "format", "markdown", "Output format: markdown or html")
+	cmd.Flags().StringVar(&opts.Number, "number", "", "Invoice number (default: next number from the local sequence)")
+	cmd.Flags().BoolVar(&opts.BillableOnly, "billable-only", false, "Invoice billable entries only")
+	cmd.Flags().StringVar(&opts.Out, "out", "", "Write output to file (default: stdout)")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Overwrite --out if it already exists")
+	cmd.Flags().StringVar(&opts.ConfigPath, "config", "", "Config file path (default: ~/.config/toggl-daily-summary/config.json)")
+	cmd.Flags().StringVar(&opts.Profile, "profile", "", "Config profile name (overrides TOGGL_PROFILE and default_profile)")
+	cmd.Flags().StringVar(&opts.WorkspaceID, "workspace", "", "Workspace ID (overrides config/env)")
+	cmd.Flags().BoolVar(&opts.DebugConfig, "debug-config", false, "Print where each config value came from to stderr")
+
+	return cmd
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"github.com/yone/toggl-daily-summary/internal/app"
+)
+
+func newProjectsCmd() *cobra.Command {
+	opts := &app.ListOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "projects",
+		Short: "Inspect Toggl projects",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List projects with their IDs, clients, status and colors",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			return app.RunProjectsList(ctx, *opts)
+		},
+	}
+	addListFlags(listCmd, opts)
+	listCmd.Flags().StringVar(&opts.WorkspaceID, "workspace", "", "Workspace ID (overrides config/env)")
+
+	cmd.AddCommand(listCmd)
+	return cmd
+}
+
+func addListFlags(cmd *cobra.Command, opts *app.ListOptions) {
+	cmd.Flags().StringVar(&opts.Format, "format", "table", "Output format: table or json")
+	cmd.Flags().StringVar(&opts.Search, "search", "", "Show only entries whose name contains this text (case-insensitive)")
+	cmd.Flags().StringVar(&opts.ConfigPath, "config", "", "Config file path (default: ~/.config/toggl-daily-summary/config.json)")
+	cmd.Flags().StringVar(&opts.Profile, "profile", "", "Config profile name (overrides TOGGL_PROFILE and default_profile)")
+}
@@ -31,10 +31,42 @@ func NewRootCmd() *cobra.Command {
 	cmd.Flags().StringVar(&opts.To, "to", "", "End date in YYYY-M-D")
 	cmd.Flags().BoolVar(&opts.Daily, "daily", false, "Split output by day when using a date range")
 	cmd.Flags().BoolVar(&opts.SeparateTaskProjects, "separate-task-projects", false, "Separate task totals by project in task list")
+	cmd.Flags().BoolVar(&opts.BillableOnly, "billable-only", false, "Summarize billable entries only")
+	cmd.Flags().BoolVar(&opts.ShowBillable, "show-billable", false, "Show billable/non-billable subtotals per project and day")
+	cmd.Flags().BoolVar(&opts.Earnings, "earnings", false, "Show earnings from configured hourly rates next to hours")
+	cmd.Flags().BoolVar(&opts.Team, "team", false, "Summarize every workspace member via the Reports API, grouped by user")
+	cmd.Flags().StringSliceVar(&opts.Users, "user", nil, "Limit --team to users by name or ID (repeatable)")
+	cmd.Flags().BoolVar(&opts.GroupByWorkspace, "group-by-workspace", false, "Group projects under their workspace")
+	cmd.Flags().StringVar(&opts.Input, "input", "", "Read time entries from a Toggl CSV export or JSON dump instead of the API")
+	cmd.Flags().StringVar(&opts.Record, "record", "", "Save raw API responses to a fixture directory (disables the cache)")
+	cmd.Flags().StringVar(&opts.Replay, "replay", "", "Serve API responses from a fixture directory recorded with --record")
+	cmd.Flags().BoolVar(&opts.NoCache, "no-cache", false, "Fetch from the API without reading or writing the local cache")
+	cmd.Flags().BoolVar(&opts.Refresh, "refresh", false, "Ignore cached data and re-fetch the whole range")
 	cmd.Flags().StringVar(&opts.Out, "out", "", "Write output to file (default: stdout)")
+	cmd.Flags().StringVar(&opts.OutDir, "out-dir", "", "Write one file per day into this directory")
+	cmd.Flags().StringVar(&opts.OutName, "out-name", "{{date}}.md", "File name template for --out-dir")
+	cmd.Flags().BoolVar(&opts.Append, "append", false, "Append to the output file instead of refusing to overwrite it")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Overwrite the output file if it already exists")
+	cmd.Flags().StringVar(&opts.Post, "post", "", "Post the summary instead of printing it: slack, webhook or email")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Print the --post payload or updated notes instead of sending or writing them")
+	cmd.Flags().BoolVar(&opts.AppendToNote, "append-to-note", false, "Insert or replace the summary in each day's note (config notes.path)")
 	cmd.Flags().StringVar(&opts.ConfigPath, "config", "", "Config file path (default: ~/.config/toggl-daily-summary/config.json)")
+	cmd.Flags().StringVar(&opts.Profile, "profile", "", "Config profile name (overrides TOGGL_PROFILE and default_profile)")
 	cmd.Flags().StringVar(&opts.WorkspaceID, "workspace", "", "Workspace ID (overrides config/env)")
-	cmd.Flags().StringVar(&opts.Format, "format", "default", "Output format: default or detail")
+	cmd.Flags().BoolVar(&opts.DebugConfig, "debug-config", false, "Print where each config value came from to stderr")
+	cmd.Flags().StringVar(&opts.Format, "format", "", "Output format: default, detail, html, chart, grid or ics (default: config format or default)")
+	cmd.Flags().BoolVar(&opts.Color, "color", false, "Colour --format chart bars with ANSI escapes using Toggl project colours")
+	cmd.Flags().StringVar(&opts.GridRows, "grid-rows", "", "Rows of --format grid: project or task (default: project)")
+	cmd.Flags().StringVar(&opts.GridStyle, "grid-style", "", "Table style of --format grid: markdown, csv or html (default: from --out extension or markdown)")
+	cmd.Flags().StringVar(&opts.TaskDelimiter, "task-delimiter", "", "Split task descriptions into a nested tree in detail format (overrides config)")
+	cmd.Flags().IntVar(&opts.Top, "top", 0, "Show only the N largest items per section and fold the rest into Other (0: no limit)")
+
+	cmd.AddCommand(newInvoiceCmd())
+	cmd.AddCommand(newBudgetCmd())
+	cmd.AddCommand(newConfigCmd())
+	cmd.AddCommand(newProjectsCmd())
+	cmd.AddCommand(newWorkspacesCmd())
+	cmd.AddCommand(newDoctorCmd())
 
 	return cmd
 }
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"github.com/yone/toggl-daily-summary/internal/app"
+)
+
+func newWorkspacesCmd() *cobra.Command {
+	opts := &app.ListOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "workspaces",
+		Short: "Inspect Toggl workspaces",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the workspaces available to the API token",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			return app.RunWorkspacesList(ctx, *opts)
+		},
+	}
+	addListFlags(listCmd, opts)
+
+	cmd.AddCommand(listCmd)
+	return cmd
+}
@@ -3,14 +3,134 @@ package config
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/invoice"
 )
 
+var sourceKeys = []string{
+	"profile",
+	"api_token",
+	"workspace_id",
+	"workspaces",
+	"base_url",
+	"reports_base_url",
+	"timezone",
+	"format",
+	"task_delimiter",
+}
+
 type Config struct {
-	APIToken    string `json:"api_token"`
-	WorkspaceID string `json:"workspace_id"`
-	BaseURL     string `json:"base_url,omitempty"`
+	APIToken        string   `json:"api_token"`
+	APITokenCommand string   `json:"api_token_command,omitempty"`
+	APITokenFile    string   `json:"api_token_file,omitempty"`
+	WorkspaceID     string   `json:"workspace_id"`
+	Workspaces      []string `json:"workspaces,omitempty"`
+	BaseURL         string   `json:"base_url,omitempty"`
+	ReportsBaseURL  string   `json:"reports_base_url,omitempty"`
+	TaskDelimiter   string   `json:"task_delimiter,omitempty"`
+	Timezone        string   `json:"timezone,omitempty"`
+	Format          string   `json:"format,omitempty"`
+	Rates           *Rates   `json:"rates,omitempty"`
+	Invoice         *Invoice `json:"invoice,omitempty"`
+	Budgets         []Budget `json:"budgets,omitempty"`
+	Slack           *Slack   `json:"slack,omitempty"`
+	Webhook         *Webhook `json:"webhook,omitempty"`
+	Email           *Email   `json:"email,omitempty"`
+	Notes           *Notes   `json:"notes,omitempty"`
+
+	DefaultProfile string             `json:"default_profile,omitempty"`
+	Profiles       map[string]Profile `json:"profiles,omitempty"`
+
+	Path    string            `json:"-"`
+	Profile string            `json:"-"`
+	Sources map[string]string `json:"-"`
+}
+
+type Profile struct {
+	APIToken        string   `json:"api_token,omitempty"`
+	APITokenCommand string   `json:"api_token_command,omitempty"`
+	APITokenFile    string   `json:"api_token_file,omitempty"`
+	WorkspaceID     string   `json:"workspace_id,omitempty"`
+	Workspaces      []string `json:"workspaces,omitempty"`
+	BaseURL         string   `json:"base_url,omitempty"`
+	ReportsBaseURL  string   `json:"reports_base_url,omitempty"`
+	TaskDelimiter   string   `json:"task_delimiter,omitempty"`
+	Timezone        string   `json:"timezone,omitempty"`
+	Format          string   `json:"format,omitempty"`
+}
+
+type Rates struct {
+	Currency   string             `json:"currency"`
+	Default    float64            `json:"default,omitempty"`
+	Workspaces map[string]float64 `json:"workspaces,omitempty"`
+	Clients    map[string]float64 `json:"clients,omitempty"`
+	Projects   map[string]float64 `json:"projects,omitempty"`
+}
+
+type Invoice struct {
+	Issuer       Party            `json:"issuer"`
+	Clients      map[string]Party `json:"clients,omitempty"`
+	TaxRate      float64          `json:"tax_rate,omitempty"`
+	Rounding     Rounding         `json:"rounding,omitempty"`
+	NumberFormat string           `json:"number_format,omitempty"`
+	SequenceFile string           `json:"sequence_file,omitempty"`
+	DueDays      int              `json:"due_days,omitempty"`
+}
+
+type Budget struct {
+	Project      string  `json:"project"`
+	TotalHours   float64 `json:"total_hours,omitempty"`
+	MonthlyHours float64 `json:"monthly_hours,omitempty"`
+	Start        string  `json:"start,omitempty"`
+	WarnPercent  float64 `json:"warn_percent,omitempty"`
+}
+
+type Slack struct {
+	WebhookURL string `json:"webhook_url"`
+}
+
+type Webhook struct {
+	URL             string            `json:"url"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Secret          string            `json:"secret,omitempty"`
+	SignatureHeader string            `json:"signature_header,omitempty"`
+	Retries         int               `json:"retries,omitempty"`
+	BodyTemplate    string            `json:"body_template,omitempty"`
+	ContentType     string            `json:"content_type,omitempty"`
+}
+
+type Email struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port,omitempty"`
+	StartTLS bool     `json:"starttls,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+	Subject  string   `json:"subject,omitempty"`
+}
+
+type Notes struct {
+	Path       string `json:"path"`
+	DateFormat string `json:"date_format,omitempty"`
+	Heading    string `json:"heading,omitempty"`
+	Template   string `json:"template,omitempty"`
+}
+
+type Party struct {
+	Name    string `json:"name"`
+	Address string `json:"address,omitempty"`
+	Email   string `json:"email,omitempty"`
+}
+
+type Rounding struct {
+	Minutes int    `json:"minutes,omitempty"`
+	Mode    string `json:"mode,omitempty"`
 }
 
 func DefaultPath() (string, error) {
@@ -33,7 +153,7 @@ func Load(path string) (Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return Config{}, nil
+			return Config{Path: path}, nil
 		}
 		return Config{}, err
 	}
@@ -42,21 +162,214 @@ func Load(path string) (Config, error) {
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return Config{}, err
 	}
+	cfg.Path = path
+	if err := checkTokenSources(cfg.APIToken, cfg.APITokenCommand, cfg.APITokenFile); err != nil {
+		return Config{}, err
+	}
+	for name, profile := range cfg.Profiles {
+		if err := checkTokenSources(profile.APIToken, profile.APITokenCommand, profile.APITokenFile); err != nil {
+			return Config{}, fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+	if cfg.Invoice != nil {
+		if err := invoice.CheckNumberFormat(cfg.Invoice.NumberFormat); err != nil {
+			return Config{}, err
+		}
+	}
+	if cfg.Webhook != nil && cfg.Webhook.Retries < 0 {
+		return Config{}, errors.New("webhook.retries must not be negative")
+	}
+
+	for key, value := range map[string]bool{
+		"api_token":        cfg.APIToken != "",
+		"workspace_id":     cfg.WorkspaceID != "",
+		"workspaces":       len(cfg.Workspaces) > 0,
+		"base_url":         cfg.BaseURL != "",
+		"reports_base_url": cfg.ReportsBaseURL != "",
+		"timezone":         cfg.Timezone != "",
+		"format":           cfg.Format != "",
+		"task_delimiter":   cfg.TaskDelimiter != "",
+	} {
+		if value {
+			cfg.SetSource(key, "config file")
+		}
+	}
+	if cfg.APITokenCommand != "" || cfg.APITokenFile != "" {
+		cfg.SetSource("api_token", tokenSource("config file", cfg.APITokenCommand, cfg.APITokenFile))
+	}
 
 	return cfg, nil
 }
 
+func ApplyProfile(cfg *Config, name string) error {
+	if cfg == nil {
+		return nil
+	}
+	source := "flag --profile"
+	if name == "" {
+		name = os.Getenv("TOGGL_PROFILE")
+		source = "env TOGGL_PROFILE"
+	}
+	if name == "" {
+		name = cfg.DefaultProfile
+		source = "config default_profile"
+	}
+	if name == "" {
+		return nil
+	}
+
+	profile, ok := cfg.Profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown profile: %s", name)
+	}
+	cfg.Profile = name
+	cfg.SetSource("profile", source)
+
+	label := "profile " + name
+	overlay := func(key string, dst *string, value string) {
+		if value != "" {
+			*dst = value
+			cfg.SetSource(key, label)
+		}
+	}
+	if profile.APIToken != "" || profile.APITokenCommand != "" || profile.APITokenFile != "" {
+		cfg.APIToken = profile.APIToken
+		cfg.APITokenCommand = profile.APITokenCommand
+		cfg.APITokenFile = profile.APITokenFile
+		cfg.SetSource("api_token", tokenSource(label, profile.APITokenCommand, profile.APITokenFile))
+	}
+	overlay("workspace_id", &cfg.WorkspaceID, profile.WorkspaceID)
+	overlay("base_url", &cfg.BaseURL, profile.BaseURL)
+	overlay("reports_base_url", &cfg.ReportsBaseURL, profile.ReportsBaseURL)
+	overlay("task_delimiter", &cfg.TaskDelimiter, profile.TaskDelimiter)
+	overlay("timezone", &cfg.Timezone, profile.Timezone)
+	overlay("format", &cfg.Format, profile.Format)
+	if len(profile.Workspaces) > 0 {
+		cfg.Workspaces = profile.Workspaces
+		cfg.SetSource("workspaces", label)
+	} else if profile.WorkspaceID != "" {
+		cfg.Workspaces = nil
+		cfg.SetSource("workspaces", label)
+	}
+	return nil
+}
+
 func ApplyEnv(cfg *Config) {
 	if cfg == nil {
 		return
 	}
 	if v := os.Getenv("TOGGL_API_TOKEN"); v != "" {
 		cfg.APIToken = v
+		cfg.APITokenCommand = ""
+		cfg.APITokenFile = ""
+		cfg.SetSource("api_token", "env TOGGL_API_TOKEN")
 	}
 	if v := os.Getenv("TOGGL_WORKSPACE_ID"); v != "" {
 		cfg.WorkspaceID = v
+		cfg.SetSource("workspace_id", "env TOGGL_WORKSPACE_ID")
+		if len(cfg.Workspaces) > 0 {
+			cfg.Workspaces = []string{v}
+			cfg.SetSource("workspaces", "env TOGGL_WORKSPACE_ID")
+		}
 	}
 	if v := os.Getenv("TOGGL_BASE_URL"); v != "" {
 		cfg.BaseURL = v
+		cfg.SetSource("base_url", "env TOGGL_BASE_URL")
+	}
+	if v := os.Getenv("TOGGL_TIMEZONE"); v != "" {
+		cfg.Timezone = v
+		cfg.SetSource("timezone", "env TOGGL_TIMEZONE")
+	}
+}
+
+func (c *Config) SetSource(key, source string) {
+	if c.Sources == nil {
+		c.Sources = map[string]string{}
+	}
+	c.Sources[key] = source
+}
+
+func (c Config) Location() (*time.Location, error) {
+	if strings.TrimSpace(c.Timezone) == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(strings.TrimSpace(c.Timezone))
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", c.Timezone, err)
+	}
+	return loc, nil
+}
+
+func FormatSources(cfg Config) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "config: %s\n", cfg.Path)
+	for _, setting := range Settings(cfg) {
+		source := setting.Source
+		if setting.Key == "profile" && setting.Value != "" {
+			source = setting.Value + " (" + source + ")"
+		}
+		fmt.Fprintf(&b, "%s: %s\n", setting.Key, source)
+	}
+	return b.String()
+}
+
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+func Settings(cfg Config) []Setting {
+	values := map[string]string{
+		"profile":          cfg.Profile,
+		"api_token":        RedactToken(cfg.APIToken),
+		"workspace_id":     cfg.WorkspaceID,
+		"workspaces":       strings.Join(cfg.Workspaces, ", "),
+		"base_url":         cfg.BaseURL,
+		"reports_base_url": cfg.ReportsBaseURL,
+		"timezone":         cfg.Timezone,
+		"format":           cfg.Format,
+		"task_delimiter":   cfg.TaskDelimiter,
+	}
+	switch {
+	case cfg.APITokenCommand != "":
+		values["api_token"] = "command: " + cfg.APITokenCommand
+	case cfg.APITokenFile != "":
+		values["api_token"] = "file: " + cfg.APITokenFile
+	}
+
+	settings := make([]Setting, 0, len(sourceKeys))
+	for _, key := range sourceKeys {
+		source := cfg.Sources[key]
+		if source == "" {
+			source = "unset"
+		}
+		settings = append(settings, Setting{Key: key, Value: values[key], Source: source})
+	}
+	return settings
+}
+
+func RedactToken(token string) string {
+	if token == "" {
+		return ""
+	}
+	if len(token) <= 8 {
+		return "********"
+	}
+	return "********" + token[len(token)-4:]
+}
+
+func Save(path string, cfg Config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
 	}
+	return os.Rename(tmp, path)
 }
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+func ResolveToken(cfg *Config, stderr io.Writer) error {
+	if cfg == nil {
+		return nil
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	switch {
+	case strings.TrimSpace(cfg.APITokenCommand) != "":
+		token, err := tokenFromCommand(cfg.APITokenCommand, stderr)
+		if err != nil {
+			return err
+		}
+		cfg.APIToken = token
+	case strings.TrimSpace(cfg.APITokenFile) != "":
+		token, err := tokenFromFile(cfg.APITokenFile, stderr)
+		if err != nil {
+			return err
+		}
+		cfg.APIToken = token
+	}
+	return nil
+}
+
+func tokenFromCommand(command string, stderr io.Writer) (string, error) {
+	var stdout bytes.Buffer
+	cmd := shellCommand(command)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("api_token_command failed: %w", err)
+	}
+	token := firstLine(stdout.String())
+	if token == "" {
+		return "", errors.New("api_token_command printed an empty token")
+	}
+	return token, nil
+}
+
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+func tokenFromFile(path string, stderr io.Writer) (string, error) {
+	path, err := ExpandHome(path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("api_token_file: %w", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o004 != 0 {
+		fmt.Fprintf(stderr, "warning: api_token_file %s is world-readable; run chmod 600 %s\n", path, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("api_token_file: %w", err)
+	}
+	token := firstLine(string(data))
+	if token == "" {
+		return "", fmt.Errorf("api_token_file %s is empty", path)
+	}
+	return token, nil
+}
+
+func ExpandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+func firstLine(value string) string {
+	value = strings.TrimSpace(value)
+	if i := strings.IndexAny(value, "\r\n"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
+
+func checkTokenSources(token, command, file string) error {
+	count := 0
+	for _, value := range []string{token, command, file} {
+		if value != "" {
+			count++
+		}
+	}
+	if count > 1 {
+		return errors.New("set only one of api_token, api_token_command or api_token_file")
+	}
+	return nil
+}
+
+func tokenSource(label, command, file string) string {
+	switch {
+	case command != "":
+		return label + " api_token_command"
+	case file != "":
+		return label + " api_token_file"
+	default:
+		return label
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestResolveTokenRunsCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	cfg := Config{APITokenCommand: "printf 'secret-token\\nsecond line\\n'"}
+	if err := ResolveToken(&cfg, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.APIToken != "secret-token" {
+		t.Fatalf("unexpected token: %q", cfg.APIToken)
+	}
+
+	cfg = Config{APITokenCommand: "exit 3"}
+	if err := ResolveToken(&cfg, &bytes.Buffer{}); err == nil {
+		t.Fatalf("expected error for failing command")
+	}
+}
+
+func TestResolveTokenReadsFileAndWarnsWhenWorldReadable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not meaningful on windows")
+	}
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+
+	var stderr bytes.Buffer
+	cfg := Config{APITokenFile: path}
+	if err := ResolveToken(&cfg, &stderr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.APIToken != "file-token" || stderr.Len() != 0 {
+		t.Fatalf("unexpected result: token=%q stderr=%q", cfg.APIToken, stderr.String())
+	}
+
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatalf("unexpected chmod error: %v", err)
+	}
+	cfg = Config{APITokenFile: path}
+	if err := ResolveToken(&cfg, &stderr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "world-readable") {
+		t.Fatalf("expected permission warning, got %q", stderr.String())
+	}
+}
+
+func TestLoadRejectsMultipleTokenSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"api_token": "plain", "api_token_command": "pass show toggl"}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestEnvTokenOverridesCommand(t *testing.T) {
+	t.Setenv("TOGGL_API_TOKEN", "env-token")
+	cfg := Config{APITokenCommand: "exit 1"}
+	ApplyEnv(&cfg)
+	if err := ResolveToken(&cfg, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.APIToken != "env-token" {
+		t.Fatalf("unexpected token: %q", cfg.APIToken)
+	}
+}
@@ -0,0 +1,401 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/invoice"
+	"github.com/yone/toggl-daily-summary/internal/webhook"
+)
+
+type Issue struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (i Issue) Error() string {
+	if i.Line == 0 {
+		return i.Message
+	}
+	return fmt.Sprintf("line %d, column %d: %s", i.Line, i.Column, i.Message)
+}
+
+func Validate(data []byte) []Issue {
+	walker, err := walkConfig(data)
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return []Issue{issueAt(data, syntaxErr.Offset-1, syntaxErr.Error())}
+		}
+		return []Issue{{Message: err.Error()}}
+	}
+	var cfg Config
+	_ = json.Unmarshal(data, &cfg)
+
+	issues := walker.issues
+	add := func(path []string, format string, args ...any) {
+		issues = append(issues, issueAt(data, walker.offset(path), fmt.Sprintf(format, args...)))
+	}
+
+	if err := checkTokenSources(cfg.APIToken, cfg.APITokenCommand, cfg.APITokenFile); err != nil {
+		add([]string{"api_token_command"}, "%s", err)
+	}
+	checkConnection(add, nil, cfg.WorkspaceID, cfg.Workspaces, cfg.BaseURL, cfg.ReportsBaseURL, cfg.Timezone, cfg.Format)
+
+	if cfg.DefaultProfile != "" {
+		if _, ok := cfg.Profiles[cfg.DefaultProfile]; !ok {
+			add([]string{"default_profile"}, "default_profile %q is not defined in profiles", cfg.DefaultProfile)
+		}
+	}
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		profile := cfg.Profiles[name]
+		prefix := []string{"profiles", name}
+		if err := checkTokenSources(profile.APIToken, profile.APITokenCommand, profile.APITokenFile); err != nil {
+			add(prefix, "profile %s: %s", name, err)
+		}
+		checkConnection(add, prefix, profile.WorkspaceID, profile.Workspaces, profile.BaseURL, profile.ReportsBaseURL, profile.Timezone, profile.Format)
+	}
+
+	if cfg.Rates != nil {
+		if strings.TrimSpace(cfg.Rates.Currency) == "" {
+			add([]string{"rates"}, "rates.currency is required")
+		}
+		if cfg.Rates.Default < 0 {
+			add([]string{"rates", "default"}, "rates.default must not be negative")
+		}
+		for _, group := range []struct {
+			key   string
+			rates map[string]float64
+		}{
+			{"workspaces", cfg.Rates.Workspaces},
+			{"clients", cfg.Rates.Clients},
+			{"projects", cfg.Rates.Projects},
+		} {
+			for name, rate := range group.rates {
+				if rate < 0 {
+					add([]string{"rates", group.key, name}, "rates.%s.%s must not be negative", group.key, name)
+				}
+			}
+		}
+	}
+
+	if cfg.Invoice != nil {
+		switch cfg.Invoice.Rounding.Mode {
+		case "", "nearest", "up", "down":
+		default:
+			add([]string{"invoice", "rounding", "mode"}, "invoice.rounding.mode must be nearest, up or down")
+		}
+		if cfg.Invoice.Rounding.Minutes < 0 {
+			add([]string{"invoice", "rounding", "minutes"}, "invoice.rounding.minutes must not be negative")
+		}
+		if cfg.Invoice.TaxRate < 0 || cfg.Invoice.TaxRate >= 1 {
+			add([]string{"invoice", "tax_rate"}, "invoice.tax_rate must be a fraction between 0 and 1 (e.g. 0.1)")
+		}
+		if cfg.Invoice.DueDays < 0 {
+			add([]string{"invoice", "due_days"}, "invoice.due_days must not be negative")
+		}
+		if err := invoice.CheckNumberFormat(cfg.Invoice.NumberFormat); err != nil {
+			add([]string{"invoice", "number_format"}, "%s", err)
+		}
+	}
+
+	if cfg.Slack != nil {
+		parsed, err := url.Parse(cfg.Slack.WebhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			add([]string{"slack", "webhook_url"}, "slack.webhook_url must be an http(s) URL")
+		}
+	}
+
+	if cfg.Webhook != nil {
+		parsed, err := url.Parse(cfg.Webhook.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			add([]string{"webhook", "url"}, "webhook.url must be an http(s) URL")
+		}
+		if cfg.Webhook.Retries < 0 {
+			add([]string{"webhook", "retries"}, "webhook.retries must not be negative")
+		}
+		if cfg.Webhook.Retries > webhook.MaxRetries {
+			add([]string{"webhook", "retries"}, "webhook.retries must be at most %d", webhook.MaxRetries)
+		}
+	}
+
+	if cfg.Email != nil {
+		if strings.TrimSpace(cfg.Email.Host) == "" {
+			add([]string{"email"}, "email.host is required")
+		}
+		if cfg.Email.Port < 0 || cfg.Email.Port > 65535 {
+			add([]string{"email", "port"}, "email.port must be between 1 and 65535")
+		}
+		if _, err := mail.ParseAddress(cfg.Email.From); err != nil {
+			add([]string{"email", "from"}, "email.from must be an email address: %s", cfg.Email.From)
+		}
+		if len(cfg.Email.To) == 0 {
+			add([]string{"email"}, "email.to needs at least one recipient")
+		}
+		for _, addr := range cfg.Email.To {
+			if _, err := mail.ParseAddress(addr); err != nil {
+				add([]string{"email", "to"}, "email.to must be email addresses: %s", addr)
+			}
+		}
+	}
+
+	if cfg.Notes != nil && !strings.Contains(cfg.Notes.Path, "{{date}}") {
+		add([]string{"notes", "path"}, "notes.path must contain {{date}}: %s", cfg.Notes.Path)
+	}
+
+	for i, item := range cfg.Budgets {
+		path := []string{"budgets", strconv.Itoa(i)}
+		if strings.TrimSpace(item.Project) == "" {
+			add(path, "budgets[%d].project is required", i)
+		}
+		if item.TotalHours <= 0 && item.MonthlyHours <= 0 {
+			add(path, "budgets[%d] needs total_hours or monthly_hours", i)
+		}
+		if item.Start != "" && !validDate(item.Start) {
+			add(path, "budgets[%d].start must be YYYY-M-D: %s", i, item.Start)
+		}
+		if item.WarnPercent < 0 || item.WarnPercent > 100 {
+			add(path, "budgets[%d].warn_percent must be between 0 and 100", i)
+		}
+	}
+
+	return issues
+}
+
+func checkConnection(add func([]string, string, ...any), prefix []string, workspaceID string, workspaces []string, baseURL, reportsBaseURL, timezone, format string) {
+	path := func(key string) []string {
+		return append(append([]string{}, prefix...), key)
+	}
+	if workspaceID != "" && !validID(workspaceID) {
+		add(path("workspace_id"), "workspace_id must be numeric: %s", workspaceID)
+	}
+	for _, id := range workspaces {
+		if !validID(id) {
+			add(path("workspaces"), "workspaces must be numeric IDs: %s", id)
+		}
+	}
+	for _, item := range []struct{ key, value string }{
+		{"base_url", baseURL},
+		{"reports_base_url", reportsBaseURL},
+	} {
+		if item.value == "" {
+			continue
+		}
+		parsed, err := url.Parse(item.value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			add(path(item.key), "%s must be an http(s) URL: %s", item.key, item.value)
+		}
+	}
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			add(path("timezone"), "unknown timezone: %s", timezone)
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "default", "detail", "html", "chart", "grid", "ics":
+	default:
+		add(path("format"), "format must be default, detail, html, chart, grid or ics: %s", format)
+	}
+}
+
+func validID(value string) bool {
+	_, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	return err == nil
+}
+
+func validDate(value string) bool {
+	for _, layout := range []string{"2006-1-2", "2006-01-02"} {
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+type configWalker struct {
+	data    []byte
+	dec     *json.Decoder
+	offsets map[string]int64
+	issues  []Issue
+}
+
+func walkConfig(data []byte) (*configWalker, error) {
+	w := &configWalker{
+		data:    data,
+		dec:     json.NewDecoder(bytes.NewReader(data)),
+		offsets: map[string]int64{},
+	}
+	if err := w.walk(reflect.TypeOf(Config{}), nil); err != nil {
+		return nil, err
+	}
+	if _, err := w.dec.Token(); err != io.EOF {
+		if err == nil {
+			err = &json.SyntaxError{Offset: w.dec.InputOffset()}
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *configWalker) offset(path []string) int64 {
+	for n := len(path); n > 0; n-- {
+		if offset, ok := w.offsets[strings.Join(path[:n], ".")]; ok {
+			return offset
+		}
+	}
+	return 0
+}
+
+func (w *configWalker) next() int64 {
+	offset := w.dec.InputOffset()
+	for offset < int64(len(w.data)) {
+		switch w.data[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+			continue
+		}
+		break
+	}
+	return offset
+}
+
+func (w *configWalker) walk(t reflect.Type, path []string) error {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	start := w.next()
+	tok, err := w.dec.Token()
+	if err != nil {
+		return err
+	}
+	mismatch := func(got string) {
+		w.issues = append(w.issues, issueAt(w.data, start, fmt.Sprintf("%s must be %s, got %s", strings.Join(path, "."), t, got)))
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		if t != nil && t.Kind() != reflect.Struct && t.Kind() != reflect.Map {
+			mismatch("object")
+			t = nil
+		}
+		for w.dec.More() {
+			keyStart := w.next()
+			keyTok, err := w.dec.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := keyTok.(string)
+			child := append(append([]string{}, path...), key)
+			w.offsets[strings.Join(child, ".")] = keyStart
+			var childType reflect.Type
+			if t != nil {
+				if t.Kind() == reflect.Map {
+					childType = t.Elem()
+				} else if field, ok := jsonField(t, key); ok {
+					childType = field
+				} else {
+					w.issues = append(w.issues, issueAt(w.data, keyStart, fmt.Sprintf("unknown field %q", strings.Join(child, "."))))
+				}
+			}
+			if err := w.walk(childType, child); err != nil {
+				return err
+			}
+		}
+		_, err := w.dec.Token()
+		return err
+	case json.Delim('['):
+		if t != nil && t.Kind() != reflect.Slice {
+			mismatch("array")
+			t = nil
+		}
+		for i := 0; w.dec.More(); i++ {
+			child := append(append([]string{}, path...), strconv.Itoa(i))
+			w.offsets[strings.Join(child, ".")] = w.next()
+			var childType reflect.Type
+			if t != nil {
+				childType = t.Elem()
+			}
+			if err := w.walk(childType, child); err != nil {
+				return err
+			}
+		}
+		_, err := w.dec.Token()
+		return err
+	}
+	if t == nil || tok == nil {
+		return nil
+	}
+	switch value := tok.(type) {
+	case string:
+		if t.Kind() != reflect.String {
+			mismatch("string")
+		}
+	case bool:
+		if t.Kind() != reflect.Bool {
+			mismatch("bool")
+		}
+	case float64:
+		switch t.Kind() {
+		case reflect.Float32, reflect.Float64:
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if value != float64(int64(value)) {
+				mismatch("number " + strconv.FormatFloat(value, 'f', -1, 64))
+			}
+		default:
+			mismatch("number")
+		}
+	}
+	return nil
+}
+
+func jsonField(t reflect.Type, key string) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.EqualFold(name, key) {
+			return field.Type, true
+		}
+	}
+	return nil, false
+}
+
+func issueAt(data []byte, offset int64, message string) Issue {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	line, column := 1, 1
+	for _, c := range data[:offset] {
+		if c == '\n' {
+			line++
+			column = 1
+			continue
+		}
+		column++
+	}
+	return Issue{Line: line, Column: column, Message: message}
+}
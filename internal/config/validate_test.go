@@ -0,0 +1,183 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateReportsSyntaxErrorPosition(t *testing.T) {
+	data := "{\n  \"api_token\": \"x\",\n  \"workspace_id\": 999x\n}\n"
+	issues := Validate([]byte(data))
+	if len(issues) != 1 {
+		t.Fatalf("expected 1 issue, got %+v", issues)
+	}
+	if issues[0].Line != 3 || issues[0].Column != 22 {
+		t.Fatalf("unexpected position: %+v", issues[0])
+	}
+}
+
+func TestValidateReportsUnknownFieldLine(t *testing.T) {
+	data := "{\n  \"api_token\": \"x\",\n  \"workspace\": \"999\"\n}\n"
+	issues := Validate([]byte(data))
+	if len(issues) != 1 || issues[0].Line != 3 || !strings.Contains(issues[0].Message, `unknown field "workspace"`) {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+}
+
+func TestValidateReportsTypeErrorLine(t *testing.T) {
+	data := "{\n  \"api_token\": \"x\",\n  \"workspace_id\": 999\n}\n"
+	issues := Validate([]byte(data))
+	if len(issues) != 1 || issues[0].Line != 3 {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+}
+
+func TestValidateChecksValues(t *testing.T) {
+	data := `{
+  "api_token": "x",
+  "workspace_id": "abc",
+  "timezone": "Mars/Olympus",
+  "format": "fancy",
+  "default_profile": "work",
+  "profiles": {
+    "home": {
+      "base_url": "ftp://example.com"
+    }
+  },
+  "rates": {"default": 100},
+  "slack": {"webhook_url": "hooks.slack.com/services/x"},
+  "webhook": {"url": "https://bots.example.com/toggl", "retries": -1},
+  "email": {"host": "smtp.example.com", "from": "not an address", "to": ["boss@example.com"]},
+  "notes": {"path": "vault/daily/today.md"},
+  "budgets": [{"project": "Alpha", "start": "Jan 1"}]
+}`
+	issues := Validate([]byte(data))
+	want := []struct {
+		line int
+		text string
+	}{
+		{3, "workspace_id must be numeric"},
+		{4, "unknown timezone"},
+		{5, "format must be default, detail, html, chart, grid or ics"},
+		{6, `default_profile "work" is not defined`},
+		{9, "base_url must be an http(s) URL"},
+		{12, "rates.currency is required"},
+		{13, "slack.webhook_url must be an http(s) URL"},
+		{14, "webhook.retries must not be negative"},
+		{15, "email.from must be an email address"},
+		{16, "notes.path must contain {{date}}"},
+		{17, "needs total_hours or monthly_hours"},
+		{17, "budgets[0].start must be YYYY-M-D"},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues, got %d: %+v", len(want), len(issues), issues)
+	}
+	for i, w := range want {
+		if issues[i].Line != w.line || !strings.Contains(issues[i].Message, w.text) {
+			t.Fatalf("issue %d: expected line %d %q, got %+v", i, w.line, w.text, issues[i])
+		}
+	}
+}
+
+func TestValidateChecksInvoiceNumberFormat(t *testing.T) {
+	data := `{
+  "invoice": {
+    "number_format": "INV-"
+  }
+}`
+	issues := Validate([]byte(data))
+	if len(issues) != 1 || issues[0].Line != 3 || !strings.Contains(issues[0].Message, "invoice.number_format must contain exactly one integer verb") {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "number_format") {
+		t.Fatalf("expected Load to reject number_format, got %v", err)
+	}
+}
+
+func TestValidateAcceptsExample(t *testing.T) {
+	data := `{
+  "api_token": "YOUR_TOGGL_API_TOKEN",
+  "workspace_id": "1234567",
+  "base_url": "https://api.track.toggl.com/api/v9"
+}`
+	if issues := Validate([]byte(data)); len(issues) != 0 {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+}
+
+func TestValidateLocatesRepeatedKeys(t *testing.T) {
+	data := `{
+  "base_url": "https://api.track.toggl.com/api/v9",
+  "profiles": {
+    "home": {
+      "base_url": "https://api.track.toggl.com/api/v9"
+    },
+    "work": {
+      "base_url": "ftp://example.com"
+    }
+  }
+}`
+	issues := Validate([]byte(data))
+	if len(issues) != 1 || issues[0].Line != 8 || !strings.Contains(issues[0].Message, "base_url must be an http(s) URL") {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+}
+
+func TestValidateReportsEveryDecodeIssue(t *testing.T) {
+	data := `{
+  "api_token": "x",
+  "workspace": "999",
+  "notes": {"path": "daily/{{date}}.md", "folder": "vault"},
+  "workspace_id": 999,
+  "timezone": "Mars/Olympus"
+}`
+	issues := Validate([]byte(data))
+	want := []struct {
+		line int
+		text string
+	}{
+		{3, `unknown field "workspace"`},
+		{4, `unknown field "notes.folder"`},
+		{5, "workspace_id must be string, got number"},
+		{6, "unknown timezone"},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues, got %d: %+v", len(want), len(issues), issues)
+	}
+	for i, w := range want {
+		if issues[i].Line != w.line || !strings.Contains(issues[i].Message, w.text) {
+			t.Fatalf("issue %d: expected line %d %q, got %+v", i, w.line, w.text, issues[i])
+		}
+	}
+}
+
+func TestValidateCapsWebhookRetries(t *testing.T) {
+	data := `{
+  "webhook": {
+    "url": "https://bots.example.com/toggl",
+    "retries": 50
+  }
+}`
+	issues := Validate([]byte(data))
+	if len(issues) != 1 || issues[0].Line != 4 || !strings.Contains(issues[0].Message, "webhook.retries must be at most 10") {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+}
+
+func TestLoadRejectsNegativeWebhookRetries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"webhook": {"url": "https://bots.example.com/toggl", "retries": -1}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "webhook.retries must not be negative") {
+		t.Fatalf("expected Load to reject negative retries, got %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultPort     = 587
+	defaultSubject  = "Toggl summary {{.Range}}"
+	defaultDialWait = 10 * time.Second
+)
+
+type Options struct {
+	Host     string
+	Port     int
+	StartTLS bool
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+	Date    time.Time
+}
+
+type SubjectData struct {
+	Start string
+	End   string
+	Range string
+	Total string
+}
+
+func RenderSubject(subjectTemplate string, data SubjectData) (string, error) {
+	if subjectTemplate == "" {
+		subjectTemplate = defaultSubject
+	}
+	tmpl, err := template.New("subject").Parse(subjectTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid email subject template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render email subject: %w", err)
+	}
+	return strings.Join(strings.Fields(buf.String()), " "), nil
+}
+
+func Build(msg Message) ([]byte, error) {
+	from, err := mail.ParseAddress(msg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address %q: %w", msg.From, err)
+	}
+	to := make([]string, 0, len(msg.To))
+	for _, addr := range msg.To {
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to address %q: %w", addr, err)
+		}
+		to = append(to, parsed.String())
+	}
+	if len(to) == 0 {
+		return nil, errors.New("no email recipients")
+	}
+	date := msg.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	boundary, err := newBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n", boundary)
+	b.WriteString("\r\n")
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		fmt.Fprintf(&b, "--%s\r\n", boundary)
+		fmt.Fprintf(&b, "Content-Type: %s; charset=utf-8\r\n", part.contentType)
+		b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+		b.WriteString("\r\n")
+		qp := quotedprintable.NewWriter(&b)
+		if _, err := qp.Write([]byte(strings.ReplaceAll(part.body, "\n", "\r\n"))); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+		b.WriteString("\r\n")
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return b.Bytes(), nil
+}
+
+func newBoundary() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "toggl-" + hex.EncodeToString(buf), nil
+}
+
+func Send(ctx context.Context, opts Options, data []byte) error {
+	port := opts.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	from, err := mail.ParseAddress(opts.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address %q: %w", opts.From, err)
+	}
+
+	dialer := &net.Dialer{Timeout: defaultDialWait}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(opts.Host, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, opts.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if opts.StartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("smtp server %s does not support STARTTLS", opts.Host)
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: opts.Host}); err != nil {
+			return err
+		}
+	}
+	if opts.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", opts.Username, opts.Password, opts.Host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, addr := range opts.To {
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			return fmt.Errorf("invalid to address %q: %w", addr, err)
+		}
+		if err := client.Rcpt(parsed.Address); err != nil {
+			return fmt.Errorf("smtp recipient %s: %w", parsed.Address, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
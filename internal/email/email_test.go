@@ -0,0 +1,197 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderSubject(t *testing.T) {
+	got, err := RenderSubject("", SubjectData{Range: "2026-01-05..2026-01-11"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "Toggl summary 2026-01-05..2026-01-11" {
+		t.Fatalf("unexpected default subject: %q", got)
+	}
+
+	got, err = RenderSubject("Weekly {{.Start}} - {{.End}} ({{.Total}})", SubjectData{Start: "2026-01-05", End: "2026-01-11", Total: "32.50h"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "Weekly 2026-01-05 - 2026-01-11 (32.50h)" {
+		t.Fatalf("unexpected subject: %q", got)
+	}
+}
+
+func TestBuildWritesMultipartAlternative(t *testing.T) {
+	data, err := Build(Message{
+		From:    "Toggl Bot <bot@example.com>",
+		To:      []string{"boss@example.com", "pm@example.com"},
+		Subject: "週報 2026-01-05",
+		Text:    "### タスク\n- Design 1.50h\n",
+		HTML:    "<p>Design 1.50h</p>",
+		Date:    time.Date(2026, 1, 11, 18, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("invalid message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "週報 2026-01-05" {
+		t.Fatalf("unexpected subject: %q (%v)", subject, err)
+	}
+	if msg.Header.Get("To") != "<boss@example.com>, <pm@example.com>" {
+		t.Fatalf("unexpected To: %q", msg.Header.Get("To"))
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("unexpected content type: %s (%v)", mediaType, err)
+	}
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	var types, bodies []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid part: %v", err)
+		}
+		body, _ := io.ReadAll(part)
+		types = append(types, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+	}
+	if len(types) != 2 || types[0] != "text/plain; charset=utf-8" || types[1] != "text/html; charset=utf-8" {
+		t.Fatalf("unexpected parts: %v", types)
+	}
+	if bodies[0] != "### タスク\r\n- Design 1.50h\r\n" || bodies[1] != "<p>Design 1.50h</p>" {
+		t.Fatalf("unexpected bodies: %q", bodies)
+	}
+}
+
+func TestSendTalksSMTP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var log []string
+		r := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+		reply("220 localhost ESMTP")
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				received <- log
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			if inData {
+				if line == "." {
+					inData = false
+					reply("250 queued")
+				} else {
+					log = append(log, "DATA "+line)
+				}
+				continue
+			}
+			log = append(log, line)
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 localhost")
+			case line == "DATA":
+				inData = true
+				reply("354 go ahead")
+			case line == "QUIT":
+				reply("221 bye")
+				received <- log
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	err = Send(context.Background(), Options{
+		Host: host,
+		Port: portNumber,
+		From: "Toggl Bot <bot@example.com>",
+		To:   []string{"boss@example.com"},
+	}, []byte("Subject: hi\r\n\r\nbody\r\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	log := strings.Join(<-received, "\n")
+	for _, want := range []string{"MAIL FROM:<bot@example.com>", "RCPT TO:<boss@example.com>", "DATA Subject: hi", "DATA body"} {
+		if !strings.Contains(log, want) {
+			t.Fatalf("expected %q in session:\n%s", want, log)
+		}
+	}
+}
+
+func TestSendRequiresStartTLSSupport(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		_, _ = io.WriteString(conn, "220 localhost ESMTP\r\n")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if strings.HasPrefix(line, "EHLO") {
+				_, _ = io.WriteString(conn, "250 localhost\r\n")
+				continue
+			}
+			_, _ = io.WriteString(conn, "250 ok\r\n")
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	err = Send(context.Background(), Options{
+		Host:     host,
+		Port:     portNumber,
+		StartTLS: true,
+		From:     "bot@example.com",
+		To:       []string{"boss@example.com"},
+	}, []byte("x"))
+	if err == nil || !strings.Contains(err.Error(), "does not support STARTTLS") {
+		t.Fatalf("expected STARTTLS error, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/toggl"
+)
+
+type Source struct {
+	entries []toggl.TimeEntry
+}
+
+func Load(path string, loc *time.Location) (*Source, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []toggl.TimeEntry
+	if isJSON(path, data) {
+		entries, err = decodeJSON(data)
+	} else {
+		entries, err = ReadCSV(bytes.NewReader(data), loc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid --input %s: %w", path, err)
+	}
+	return &Source{entries: entries}, nil
+}
+
+func decodeJSON(data []byte) ([]toggl.TimeEntry, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\ufeff")))
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var wrapper struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(trimmed, &wrapper); err != nil {
+			return nil, err
+		}
+		if len(wrapper.Data) == 0 {
+			return nil, errors.New(`JSON object must have a "data" array of time entries`)
+		}
+		trimmed = wrapper.Data
+	}
+	return toggl.DecodeTimeEntries(bytes.NewReader(trimmed))
+}
+
+func isJSON(path string, data []byte) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return true
+	case ".csv":
+		return false
+	}
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\ufeff")))
+	return len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{')
+}
+
+func (s *Source) FetchTimeEntries(_ context.Context, start, end time.Time) ([]toggl.TimeEntry, error) {
+	out := make([]toggl.TimeEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		if entry.Start.Before(start) || !entry.Start.Before(end) {
+			continue
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+func (s *Source) FetchProjects(_ context.Context, _ string) (map[int64]toggl.Project, error) {
+	projects := map[int64]toggl.Project{}
+	for _, entry := range s.entries {
+		if entry.ProjectID != 0 && entry.ProjectName != "" {
+			projects[entry.ProjectID] = toggl.Project{
+				ID:          entry.ProjectID,
+				WorkspaceID: entry.WorkspaceID,
+				Name:        entry.ProjectName,
+			}
+		}
+	}
+	return projects, nil
+}
+
+func (s *Source) FetchClients(_ context.Context, _ string) (map[int64]string, error) {
+	return map[int64]string{}, nil
+}
+
+func ReadCSV(r io.Reader, loc *time.Location) ([]toggl.TimeEntry, error) {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"description", "start date", "start time"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing CSV column: %s", required)
+		}
+	}
+
+	var entries []toggl.TimeEntry
+	line := 1
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line++
+
+		field := func(name string) string {
+			idx, ok := columns[name]
+			if !ok || idx >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[idx])
+		}
+
+		start, err := time.ParseInLocation("2006-01-02 15:04:05", field("start date")+" "+field("start time"), loc)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid start: %w", line, err)
+		}
+		duration, err := csvDuration(field("duration"), field("end date"), field("end time"), start, loc)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		var id int64
+		if value := field("id"); value != "" {
+			if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+				id = parsed
+			}
+		}
+
+		entries = append(entries, toggl.TimeEntry{
+			ID:          id,
+			Description: field("description"),
+			Start:       start,
+			Duration:    duration,
+			ProjectName: field("project"),
+			ClientName:  field("client"),
+			Billable:    strings.EqualFold(field("billable"), "yes") || strings.EqualFold(field("billable"), "true"),
+			Tags:        csvTags(field("tags")),
+		})
+	}
+	return entries, nil
+}
+
+func csvTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func csvDuration(value, endDate, endTime string, start time.Time, loc *time.Location) (time.Duration, error) {
+	if value != "" {
+		parts := strings.Split(value, ":")
+		if len(parts) == 3 {
+			h, errH := strconv.Atoi(parts[0])
+			m, errM := strconv.Atoi(parts[1])
+			sec, errS := strconv.Atoi(parts[2])
+			if errH == nil && errM == nil && errS == nil {
+				return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec)*time.Second, nil
+			}
+		}
+		return 0, fmt.Errorf("invalid duration: %s", value)
+	}
+	if endDate == "" || endTime == "" {
+		return 0, errors.New("missing duration or end time")
+	}
+	end, err := time.ParseInLocation("2006-01-02 15:04:05", endDate+" "+endTime, loc)
+	if err != nil {
+		return 0, fmt.Errorf("invalid end: %w", err)
+	}
+	return end.Sub(start), nil
+}
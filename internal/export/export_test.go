@@ -0,0 +1,109 @@
+package export
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadCSVDetailedReport(t *testing.T) {
+	data := "" +
+		"User,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags,Amount ()\n" +
+		"Yone,yone@example.com,Acme,Alpha,,Design,Yes,2026-01-10,09:00:00,2026-01-10,10:30:00,01:30:00,\"review, client\",\n" +
+		"Yone,yone@example.com,,,,Admin,No,2026-01-10,11:00:00,2026-01-10,11:15:00,,,\n"
+
+	entries, err := ReadCSV(strings.NewReader(data), time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	first := entries[0]
+	if first.Description != "Design" || first.ProjectName != "Alpha" || first.ClientName != "Acme" || !first.Billable {
+		t.Fatalf("unexpected first entry: %+v", first)
+	}
+	if !first.Start.Equal(time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)) || first.Duration != 90*time.Minute {
+		t.Fatalf("unexpected first entry timing: %+v", first)
+	}
+	if strings.Join(first.Tags, "|") != "review|client" || entries[1].Tags != nil {
+		t.Fatalf("unexpected tags: %v / %v", first.Tags, entries[1].Tags)
+	}
+	if first.ID != 0 || entries[1].ID != 0 {
+		t.Fatalf("expected no IDs without an id column, got %d / %d", first.ID, entries[1].ID)
+	}
+	if entries[1].Duration != 15*time.Minute || entries[1].Billable {
+		t.Fatalf("unexpected second entry: %+v", entries[1])
+	}
+}
+
+func TestReadCSVMissingColumn(t *testing.T) {
+	if _, err := ReadCSV(strings.NewReader("Project,Duration\nAlpha,01:00:00\n"), time.UTC); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestLoadJSONDumpFiltersRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.json")
+	data := `[
+	  {"id":1,"description":"Design","start":"2026-01-10T09:00:00Z","duration":3600,"project_id":111,"project_name":"Alpha"},
+	  {"id":2,"description":"Later","start":"2026-01-11T09:00:00Z","duration":3600},
+	  {"id":3,"description":"Running","start":"2026-01-10T12:00:00Z","duration":-1}
+	]`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+
+	source, err := Load(path, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries, err := source.FetchTimeEntries(context.Background(),
+		time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ID != 1 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	projects, err := source.FetchProjects(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if projects[111].Name != "Alpha" {
+		t.Fatalf("unexpected projects: %+v", projects)
+	}
+}
+
+func TestLoadJSONAcceptsDataWrapper(t *testing.T) {
+	dir := t.TempDir()
+	wrapped := filepath.Join(dir, "wrapped.json")
+	data := `{"data":[{"id":7,"description":"Design","start":"2026-01-10T09:00:00Z","duration":3600}]}`
+	if err := os.WriteFile(wrapped, []byte(data), 0o644); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	source, err := Load(wrapped, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries, err := source.FetchTimeEntries(context.Background(),
+		time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC),
+	)
+	if err != nil || len(entries) != 1 || entries[0].ID != 7 {
+		t.Fatalf("unexpected entries: %+v (%v)", entries, err)
+	}
+
+	other := filepath.Join(dir, "other.json")
+	if err := os.WriteFile(other, []byte(`{"items":[]}`), 0o644); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	if _, err := Load(other, time.UTC); err == nil || !strings.Contains(err.Error(), `"data" array`) {
+		t.Fatalf("expected data wrapper error, got %v", err)
+	}
+}
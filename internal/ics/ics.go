@@ -0,0 +1,112 @@
+package ics
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yone/toggl-daily-summary/internal/toggl"
+)
+
+const (
+	productID     = "-//toggl-daily-summary//EN"
+	uidDomain     = "toggl-daily-summary"
+	timestamp     = "20060102T150405Z"
+	maxLineOctets = 75
+)
+
+type Options struct {
+	Name string
+	Now  time.Time
+}
+
+func Format(entries []toggl.TimeEntry, opts Options) string {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	stamp := now.UTC().Format(timestamp)
+
+	var b strings.Builder
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+productID)
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	if opts.Name != "" {
+		writeLine(&b, "X-WR-CALNAME:"+escapeText(opts.Name))
+	}
+	for _, entry := range entries {
+		if entry.Duration <= 0 {
+			continue
+		}
+		summary := strings.TrimSpace(entry.Description)
+		if summary == "" {
+			summary = "No Description"
+		}
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, "UID:"+uid(entry))
+		writeLine(&b, "DTSTAMP:"+stamp)
+		writeLine(&b, "DTSTART:"+entry.Start.UTC().Format(timestamp))
+		writeLine(&b, "DTEND:"+entry.Start.Add(entry.Duration).UTC().Format(timestamp))
+		writeLine(&b, "SUMMARY:"+escapeText(summary))
+		if categories := categoriesOf(entry); len(categories) > 0 {
+			writeLine(&b, "CATEGORIES:"+strings.Join(categories, ","))
+		}
+		writeLine(&b, "TRANSP:TRANSPARENT")
+		writeLine(&b, "END:VEVENT")
+	}
+	writeLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+func categoriesOf(entry toggl.TimeEntry) []string {
+	var categories []string
+	seen := map[string]bool{}
+	for _, value := range append([]string{entry.ProjectName}, entry.Tags...) {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		categories = append(categories, escapeText(value))
+	}
+	return categories
+}
+
+func escapeText(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(value)
+}
+
+func writeLine(b *strings.Builder, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func uid(entry toggl.TimeEntry) string {
+	if entry.ID != 0 {
+		return fmt.Sprintf("%d@%s", entry.ID, uidDomain)
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\n%d\n%s", entry.Start.UTC().Format(time.RFC3339), int64(entry.Duration/time.Second), entry.Description)))
+	return hex.EncodeToString(sum[:8]) + "@" + uidDomain
+}
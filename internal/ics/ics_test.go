@@ -0,0 +1,97 @@
+package ics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/toggl"
+)
+
+func TestFormatWritesOneEventPerEntry(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	entries := []toggl.TimeEntry{
+		{ID: 42, Description: "Design; review, v2", Start: time.Date(2026, 1, 10, 9, 0, 0, 0, jst), Duration: 90 * time.Minute, ProjectName: "Alpha", Tags: []string{"client", "Alpha"}},
+		{ID: 43, Start: time.Date(2026, 1, 10, 11, 0, 0, 0, jst), Duration: 15 * time.Minute},
+		{ID: 44, Description: "Zero", Start: time.Date(2026, 1, 10, 12, 0, 0, 0, jst)},
+	}
+
+	got := Format(entries, Options{Name: "Toggl", Now: time.Date(2026, 1, 11, 8, 30, 0, 0, time.UTC)})
+	want := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//toggl-daily-summary//EN\r\n" +
+		"CALSCALE:GREGORIAN\r\n" +
+		"X-WR-CALNAME:Toggl\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:42@toggl-daily-summary\r\n" +
+		"DTSTAMP:20260111T083000Z\r\n" +
+		"DTSTART:20260110T000000Z\r\n" +
+		"DTEND:20260110T013000Z\r\n" +
+		"SUMMARY:Design\\; review\\, v2\r\n" +
+		"CATEGORIES:Alpha,client\r\n" +
+		"TRANSP:TRANSPARENT\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:43@toggl-daily-summary\r\n" +
+		"DTSTAMP:20260111T083000Z\r\n" +
+		"DTSTART:20260110T020000Z\r\n" +
+		"DTEND:20260110T021500Z\r\n" +
+		"SUMMARY:No Description\r\n" +
+		"TRANSP:TRANSPARENT\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	if got != want {
+		t.Fatalf("unexpected calendar:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestFormatDerivesStableUIDWithoutEntryID(t *testing.T) {
+	entry := toggl.TimeEntry{Description: "Design", Start: time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC), Duration: time.Hour}
+	moved := entry
+	moved.Start = entry.Start.Add(time.Hour)
+
+	first := Format([]toggl.TimeEntry{entry}, Options{})
+	again := Format([]toggl.TimeEntry{{Description: "Other", Start: entry.Start, Duration: time.Minute}, entry}, Options{})
+	other := Format([]toggl.TimeEntry{moved}, Options{})
+
+	line := uidLine(t, first)
+	if strings.HasPrefix(line, "UID:0@") || !strings.HasSuffix(line, "@toggl-daily-summary") {
+		t.Fatalf("expected a hashed UID, got %q", line)
+	}
+	if !strings.Contains(again, line+"\r\n") {
+		t.Fatalf("expected %q to be stable across exports:\n%s", line, again)
+	}
+	if uidLine(t, other) == line {
+		t.Fatalf("expected a different UID for a different entry, got %q", line)
+	}
+}
+
+func uidLine(t *testing.T, calendar string) string {
+	t.Helper()
+	for _, line := range strings.Split(calendar, "\r\n") {
+		if strings.HasPrefix(line, "UID:") {
+			return line
+		}
+	}
+	t.Fatalf("no UID in calendar:\n%s", calendar)
+	return ""
+}
+
+func TestFormatFoldsLongLinesOnRuneBoundaries(t *testing.T) {
+	description := strings.Repeat("設計レビュー", 10)
+	got := Format([]toggl.TimeEntry{{ID: 1, Description: description, Start: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC), Duration: time.Hour}}, Options{})
+
+	var unfolded strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Fatalf("line longer than 75 octets: %q", line)
+		}
+		if !strings.HasPrefix(line, " ") && unfolded.Len() > 0 {
+			unfolded.WriteString("\n")
+		}
+		unfolded.WriteString(strings.TrimPrefix(line, " "))
+	}
+	if !strings.Contains(unfolded.String(), "SUMMARY:"+description+"\n") {
+		t.Fatalf("expected folded summary to unfold to the description:\n%s", got)
+	}
+}
@@ -0,0 +1,280 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/summary"
+)
+
+const dateLayout = "2006-01-02"
+
+type Party struct {
+	Name    string
+	Address string
+	Email   string
+}
+
+type Line struct {
+	Description string
+	Hours       float64
+	Rate        float64
+	Amount      float64
+}
+
+type Invoice struct {
+	Number      string
+	IssueDate   time.Time
+	DueDate     time.Time
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Issuer      Party
+	Recipient   Party
+	Currency    string
+	Lines       []Line
+	Subtotal    float64
+	TaxRate     float64
+	Tax         float64
+	Total       float64
+}
+
+type BuildOptions struct {
+	GroupBy         string
+	RoundingMinutes int
+	RoundingMode    string
+}
+
+func BuildLines(buckets []summary.Bucket, opts BuildOptions) ([]Line, error) {
+	type lineAgg struct {
+		total    time.Duration
+		earnings float64
+	}
+	grouped := map[string]*lineAgg{}
+	add := func(name string, total time.Duration, earnings float64) {
+		agg, ok := grouped[name]
+		if !ok {
+			agg = &lineAgg{}
+			grouped[name] = agg
+		}
+		agg.total += total
+		agg.earnings += earnings
+	}
+
+	switch opts.GroupBy {
+	case "", "project":
+		for _, bucket := range buckets {
+			for _, project := range bucket.Projects {
+				add(project.Name, project.Total, project.Earnings)
+			}
+		}
+	case "task":
+		for _, bucket := range buckets {
+			for _, task := range bucket.Tasks {
+				add(task.Name, task.Total, task.Earnings)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid --group-by: %s", opts.GroupBy)
+	}
+
+	names := make([]string, 0, len(grouped))
+	for name := range grouped {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]Line, 0, len(names))
+	for _, name := range names {
+		agg := grouped[name]
+		if agg.total <= 0 {
+			continue
+		}
+		rate := agg.earnings / agg.total.Hours()
+		hours, err := roundHours(agg.total, opts.RoundingMinutes, opts.RoundingMode)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, Line{
+			Description: name,
+			Hours:       hours,
+			Rate:        roundMoney(rate),
+			Amount:      roundMoney(hours * rate),
+		})
+	}
+	return lines, nil
+}
+
+func Totals(inv *Invoice) {
+	var subtotal float64
+	for _, line := range inv.Lines {
+		subtotal += line.Amount
+	}
+	inv.Subtotal = roundMoney(subtotal)
+	inv.Tax = roundMoney(inv.Subtotal * inv.TaxRate)
+	inv.Total = roundMoney(inv.Subtotal + inv.Tax)
+}
+
+func roundHours(d time.Duration, minutes int, mode string) (float64, error) {
+	if minutes <= 0 {
+		return math.Round(d.Hours()*100) / 100, nil
+	}
+	unit := time.Duration(minutes) * time.Minute
+	units := float64(d) / float64(unit)
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", "nearest":
+		units = math.Round(units)
+	case "up":
+		units = math.Ceil(units)
+	case "down":
+		units = math.Floor(units)
+	default:
+		return 0, fmt.Errorf("invalid rounding mode: %s", mode)
+	}
+	return units * unit.Hours(), nil
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func RenderMarkdown(inv Invoice) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# 請求書 %s\n", inv.Number)
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "- 発行日: %s\n", inv.IssueDate.Format(dateLayout))
+	fmt.Fprintf(&b, "- 対象期間: %s\n", formatPeriod(inv))
+	if !inv.DueDate.IsZero() {
+		fmt.Fprintf(&b, "- 支払期限: %s\n", inv.DueDate.Format(dateLayout))
+	}
+
+	b.WriteString("\n")
+	b.WriteString("## 請求元\n")
+	writeParty(&b, inv.Issuer)
+	b.WriteString("\n")
+	b.WriteString("## 請求先\n")
+	writeParty(&b, inv.Recipient)
+
+	b.WriteString("\n")
+	b.WriteString("## 明細\n")
+	b.WriteString("\n")
+	b.WriteString("| 品目 | 時間 | 単価 | 金額 |\n")
+	b.WriteString("| --- | ---: | ---: | ---: |\n")
+	for _, line := range inv.Lines {
+		fmt.Fprintf(&b, "| %s | %.2fh | %s | %s |\n",
+			escapeCell(line.Description),
+			line.Hours,
+			summary.FormatMoney(line.Rate, inv.Currency),
+			summary.FormatMoney(line.Amount, inv.Currency),
+		)
+	}
+
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "- 小計: %s\n", summary.FormatMoney(inv.Subtotal, inv.Currency))
+	fmt.Fprintf(&b, "- 税 (%s): %s\n", formatPercent(inv.TaxRate), summary.FormatMoney(inv.Tax, inv.Currency))
+	fmt.Fprintf(&b, "- 合計: %s\n", summary.FormatMoney(inv.Total, inv.Currency))
+	return b.String()
+}
+
+func writeParty(b *strings.Builder, party Party) {
+	for _, value := range []string{party.Name, party.Address, party.Email} {
+		for _, line := range strings.Split(strings.TrimSpace(value), "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			fmt.Fprintf(b, "%s  \n", strings.TrimSpace(line))
+		}
+	}
+}
+
+func escapeCell(value string) string {
+	return strings.ReplaceAll(value, "|", "\\|")
+}
+
+func formatPeriod(inv Invoice) string {
+	start := inv.PeriodStart.Format(dateLayout)
+	end := inv.PeriodEnd.Format(dateLayout)
+	if start == end {
+		return start
+	}
+	return fmt.Sprintf("%s..%s", start, end)
+}
+
+func formatPercent(rate float64) string {
+	return fmt.Sprintf("%g%%", math.Round(rate*10000)/100)
+}
+
+var htmlTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"date":    func(t time.Time) string { return t.Format(dateLayout) },
+	"money":   summary.FormatMoney,
+	"hours":   func(h float64) string { return fmt.Sprintf("%.2fh", h) },
+	"percent": formatPercent,
+	"lines": func(value string) []string {
+		var out []string
+		for _, line := range strings.Split(strings.TrimSpace(value), "\n") {
+			if strings.TrimSpace(line) != "" {
+				out = append(out, strings.TrimSpace(line))
+			}
+		}
+		return out
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="ja">
+<head>
+<meta charset="utf-8">
+<title>請求書 {{.Invoice.Number}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ccc; padding: 0.4em; }
+td.num, th.num { text-align: right; }
+.parties { display: flex; gap: 4em; }
+</style>
+</head>
+<body>
+<h1>請求書 {{.Invoice.Number}}</h1>
+<p>発行日: {{date .Invoice.IssueDate}}<br>
+対象期間: {{.Period}}{{if not .Invoice.DueDate.IsZero}}<br>
+支払期限: {{date .Invoice.DueDate}}{{end}}</p>
+<div class="parties">
+<div>
+<h2>請求元</h2>
+<p>{{range lines .Invoice.Issuer.Name}}{{.}}<br>{{end}}{{range lines .Invoice.Issuer.Address}}{{.}}<br>{{end}}{{range lines .Invoice.Issuer.Email}}{{.}}<br>{{end}}</p>
+</div>
+<div>
+<h2>請求先</h2>
+<p>{{range lines .Invoice.Recipient.Name}}{{.}}<br>{{end}}{{range lines .Invoice.Recipient.Address}}{{.}}<br>{{end}}{{range lines .Invoice.Recipient.Email}}{{.}}<br>{{end}}</p>
+</div>
+</div>
+<h2>明細</h2>
+<table>
+<tr><th>品目</th><th class="num">時間</th><th class="num">単価</th><th class="num">金額</th></tr>
+{{- range .Invoice.Lines}}
+<tr><td>{{.Description}}</td><td class="num">{{hours .Hours}}</td><td class="num">{{money .Rate $.Invoice.Currency}}</td><td class="num">{{money .Amount $.Invoice.Currency}}</td></tr>
+{{- end}}
+<tr><td colspan="3" class="num">小計</td><td class="num">{{money .Invoice.Subtotal .Invoice.Currency}}</td></tr>
+<tr><td colspan="3" class="num">税 ({{percent .Invoice.TaxRate}})</td><td class="num">{{money .Invoice.Tax .Invoice.Currency}}</td></tr>
+<tr><th colspan="3" class="num">合計</th><th class="num">{{money .Invoice.Total .Invoice.Currency}}</th></tr>
+</table>
+</body>
+</html>
+`))
+
+func RenderHTML(inv Invoice) (string, error) {
+	var buf bytes.Buffer
+	err := htmlTemplate.Execute(&buf, struct {
+		Invoice Invoice
+		Period  string
+	}{
+		Invoice: inv,
+		Period:  formatPeriod(inv),
+	})
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,160 @@
+package invoice
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/summary"
+)
+
+func TestBuildLinesRoundsUpPerLine(t *testing.T) {
+	buckets := []summary.Bucket{
+		{
+			Projects: []summary.ProjectBucket{
+				{Name: "Beta", Total: 50 * time.Minute, Earnings: 5000 * 50 / 60.0},
+				{Name: "Alpha", Total: 2 * time.Hour, Earnings: 20000},
+			},
+		},
+	}
+
+	lines, err := BuildLines(buckets, BuildOptions{
+		GroupBy:         "project",
+		RoundingMinutes: 15,
+		RoundingMode:    "up",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	if lines[0].Description != "Alpha" || lines[0].Hours != 2 || lines[0].Amount != 20000 {
+		t.Fatalf("unexpected first line: %+v", lines[0])
+	}
+	if lines[1].Description != "Beta" || lines[1].Hours != 1 || lines[1].Rate != 5000 || lines[1].Amount != 5000 {
+		t.Fatalf("unexpected second line: %+v", lines[1])
+	}
+}
+
+func TestBuildLinesRejectsUnknownGroup(t *testing.T) {
+	if _, err := BuildLines(nil, BuildOptions{GroupBy: "client"}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	inv := Invoice{
+		Number:      "INV-0001",
+		IssueDate:   time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		PeriodStart: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC),
+		Issuer:      Party{Name: "Yone", Email: "yone@example.com"},
+		Recipient:   Party{Name: "Acme Inc.", Address: "1-2-3 Tokyo"},
+		Currency:    "JPY",
+		Lines: []Line{
+			{Description: "Alpha", Hours: 2, Rate: 10000, Amount: 20000},
+		},
+		TaxRate: 0.1,
+	}
+	Totals(&inv)
+
+	got := RenderMarkdown(inv)
+	want := "" +
+		"# 請求書 INV-0001\n" +
+		"\n" +
+		"- 発行日: 2026-02-01\n" +
+		"- 対象期間: 2026-01-01..2026-01-31\n" +
+		"\n" +
+		"## 請求元\n" +
+		"Yone  \n" +
+		"yone@example.com  \n" +
+		"\n" +
+		"## 請求先\n" +
+		"Acme Inc.  \n" +
+		"1-2-3 Tokyo  \n" +
+		"\n" +
+		"## 明細\n" +
+		"\n" +
+		"| 品目 | 時間 | 単価 | 金額 |\n" +
+		"| --- | ---: | ---: | ---: |\n" +
+		"| Alpha | 2.00h | JPY 10000.00 | JPY 20000.00 |\n" +
+		"\n" +
+		"- 小計: JPY 20000.00\n" +
+		"- 税 (10%): JPY 2000.00\n" +
+		"- 合計: JPY 22000.00\n"
+
+	if got != want {
+		t.Fatalf("unexpected markdown:\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+
+	html, err := RenderHTML(inv)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(html, "JPY 22000.00") || !strings.Contains(html, "Acme Inc.") {
+		t.Fatalf("unexpected html: %s", html)
+	}
+}
+
+func TestSequenceAdvancesOnlyOnCommit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "invoice-sequence")
+
+	seq, err := OpenSequence(path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seq.Number() != "INV-0001" {
+		t.Fatalf("unexpected number: %s", seq.Number())
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected no sequence file before commit, got %v", err)
+	}
+	if err := seq.Commit(); err != nil {
+		t.Fatalf("unexpected commit error: %v", err)
+	}
+
+	seq, err = OpenSequence(path, "ACME-%03d")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seq.Number() != "ACME-002" {
+		t.Fatalf("unexpected number: %s", seq.Number())
+	}
+	stale, err := OpenSequence(path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := seq.Commit(); err != nil {
+		t.Fatalf("unexpected commit error: %v", err)
+	}
+	if err := stale.Commit(); err == nil || !strings.Contains(err.Error(), "changed while the invoice was being created") {
+		t.Fatalf("expected concurrent commit error, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "2" {
+		t.Fatalf("unexpected sequence file: %q", string(data))
+	}
+}
+
+func TestCheckNumberFormat(t *testing.T) {
+	for _, format := range []string{"", "INV-%04d", "%d", "100%% %03d"} {
+		if err := CheckNumberFormat(format); err != nil {
+			t.Fatalf("expected %q to be valid: %v", format, err)
+		}
+	}
+	for _, format := range []string{"INV-", "INV-%s", "%d-%d"} {
+		if err := CheckNumberFormat(format); err == nil {
+			t.Fatalf("expected %q to be rejected", format)
+		}
+	}
+	if _, err := OpenSequence(filepath.Join(t.TempDir(), "seq"), "INV-"); err == nil {
+		t.Fatal("expected OpenSequence to reject a format without a verb")
+	}
+}
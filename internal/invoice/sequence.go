@@ -0,0 +1,104 @@
+package invoice
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const defaultNumberFormat = "INV-%04d"
+
+type Sequence struct {
+	path    string
+	format  string
+	current int
+}
+
+func DefaultSequencePath() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "toggl-daily-summary", "invoice-sequence"), nil
+}
+
+func CheckNumberFormat(format string) error {
+	if format != "" && strings.Contains(fmt.Sprintf(format, 1), "%!") {
+		return fmt.Errorf("invoice.number_format must contain exactly one integer verb such as %%04d: %q", format)
+	}
+	return nil
+}
+
+func OpenSequence(path, format string) (*Sequence, error) {
+	if path == "" {
+		defaultPath, err := DefaultSequencePath()
+		if err != nil {
+			return nil, err
+		}
+		path = defaultPath
+	}
+	if format == "" {
+		format = defaultNumberFormat
+	}
+	if err := CheckNumberFormat(format); err != nil {
+		return nil, err
+	}
+	current, err := readSequence(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Sequence{path: path, format: format, current: current}, nil
+}
+
+func (s *Sequence) Number() string {
+	return fmt.Sprintf(s.format, s.current+1)
+}
+
+func (s *Sequence) Commit() error {
+	current, err := readSequence(s.path)
+	if err != nil {
+		return err
+	}
+	if current != s.current {
+		return fmt.Errorf("invoice sequence %s changed while the invoice was being created (expected %d, found %d)", s.path, s.current, current)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(strconv.Itoa(s.current+1)+"\n"), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	s.current++
+	return nil
+}
+
+func readSequence(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		return 0, nil
+	}
+	current, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid invoice sequence file %s: %w", path, err)
+	}
+	return current, nil
+}
@@ -0,0 +1,60 @@
+package note
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	StartMarker = "<!-- toggl-daily-summary:start -->"
+	EndMarker   = "<!-- toggl-daily-summary:end -->"
+
+	DefaultHeading    = "## Toggl"
+	DefaultDateLayout = "2006-01-02"
+)
+
+func Expand(template string, date time.Time, layout string) string {
+	if layout == "" {
+		layout = DefaultDateLayout
+	}
+	return strings.ReplaceAll(template, "{{date}}", date.Format(layout))
+}
+
+func Upsert(content, heading, summary string) string {
+	if heading == "" {
+		heading = DefaultHeading
+	}
+	block := StartMarker + "\n" + strings.TrimRight(summary, "\n") + "\n" + EndMarker
+
+	if start := strings.Index(content, StartMarker); start >= 0 {
+		if end := strings.Index(content[start:], EndMarker); end >= 0 {
+			end += start + len(EndMarker)
+			return content[:start] + block + content[end:]
+		}
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	offset := 0
+	for _, line := range lines {
+		offset += len(line)
+		if strings.TrimSpace(line) != heading {
+			continue
+		}
+		head, tail := content[:offset], content[offset:]
+		if !strings.HasSuffix(head, "\n") {
+			head += "\n"
+		}
+		if tail != "" && !strings.HasPrefix(tail, "\n") {
+			tail = "\n" + tail
+		}
+		return head + "\n" + block + "\n" + tail
+	}
+
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	return content + heading + "\n\n" + block + "\n"
+}
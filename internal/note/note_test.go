@@ -0,0 +1,45 @@
+package note
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpand(t *testing.T) {
+	date := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	if got := Expand("vault/daily/{{date}}.md", date, ""); got != "vault/daily/2026-01-10.md" {
+		t.Fatalf("unexpected path: %s", got)
+	}
+	if got := Expand("journals/{{date}}.md", date, "2006_01_02"); got != "journals/2026_01_10.md" {
+		t.Fatalf("unexpected path: %s", got)
+	}
+}
+
+func TestUpsertAppendsHeadingWhenMissing(t *testing.T) {
+	got := Upsert("# 2026-01-10\n\nMorning notes", "", "- Alpha 1.00h\n")
+	want := "# 2026-01-10\n\nMorning notes\n\n## Toggl\n\n" + StartMarker + "\n- Alpha 1.00h\n" + EndMarker + "\n"
+	if got != want {
+		t.Fatalf("unexpected note:\n%s", got)
+	}
+}
+
+func TestUpsertInsertsUnderExistingHeading(t *testing.T) {
+	content := "# Daily\n## Time\n## Journal\nWent well\n"
+	got := Upsert(content, "## Time", "- Alpha 1.00h\n")
+	want := "# Daily\n## Time\n\n" + StartMarker + "\n- Alpha 1.00h\n" + EndMarker + "\n\n## Journal\nWent well\n"
+	if got != want {
+		t.Fatalf("unexpected note:\n%s", got)
+	}
+}
+
+func TestUpsertReplacesBetweenMarkers(t *testing.T) {
+	first := Upsert("# Daily\n## Time\n## Journal\n", "## Time", "- Alpha 1.00h\n")
+	second := Upsert(first, "## Time", "- Alpha 2.00h\n")
+	want := "# Daily\n## Time\n\n" + StartMarker + "\n- Alpha 2.00h\n" + EndMarker + "\n\n## Journal\n"
+	if second != want {
+		t.Fatalf("unexpected note:\n%s", second)
+	}
+	if again := Upsert(second, "## Time", "- Alpha 2.00h\n"); again != second {
+		t.Fatalf("expected idempotent update:\n%s", again)
+	}
+}
@@ -0,0 +1,195 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHTTPTimeout = 10 * time.Second
+
+	maxBlocksPerMessage = 50
+	maxSectionText      = 3000
+	maxHeaderText       = 150
+	maxFallbackText     = 3000
+)
+
+var hoursPattern = regexp.MustCompile(`(\d+\.\d{2}h)`)
+
+type Message struct {
+	Text   string  `json:"text"`
+	Blocks []Block `json:"blocks"`
+}
+
+type Block struct {
+	Type string `json:"type"`
+	Text *Text  `json:"text,omitempty"`
+}
+
+type Text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type Client struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+func NewClient(webhookURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	return &Client{
+		webhookURL: webhookURL,
+		httpClient: httpClient,
+	}
+}
+
+func (c *Client) Post(ctx context.Context, messages []Message) error {
+	for i, message := range messages {
+		if err := c.post(ctx, message); err != nil {
+			return fmt.Errorf("slack message %d/%d: %w", i+1, len(messages), err)
+		}
+	}
+	return nil
+}
+
+func (c *Client) post(ctx context.Context, message Message) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		if text := strings.TrimSpace(string(data)); text != "" {
+			return fmt.Errorf("slack webhook error: %s: %s", resp.Status, text)
+		}
+		return fmt.Errorf("slack webhook error: %s", resp.Status)
+	}
+	return nil
+}
+
+func Messages(markdown string) []Message {
+	blocks := Blocks(markdown)
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	var messages []Message
+	for start := 0; start < len(blocks); start += maxBlocksPerMessage {
+		end := start + maxBlocksPerMessage
+		if end > len(blocks) {
+			end = len(blocks)
+		}
+		chunk := blocks[start:end]
+		messages = append(messages, Message{
+			Text:   fallbackText(chunk),
+			Blocks: chunk,
+		})
+	}
+	return messages
+}
+
+func Blocks(markdown string) []Block {
+	var blocks []Block
+	var section []string
+	flush := func() {
+		for _, text := range splitText(section, maxSectionText) {
+			blocks = append(blocks, Block{Type: "section", Text: &Text{Type: "mrkdwn", Text: text}})
+		}
+		section = nil
+	}
+
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "## "):
+			flush()
+			blocks = append(blocks, Block{Type: "header", Text: &Text{
+				Type: "plain_text",
+				Text: truncate(strings.TrimPrefix(trimmed, "## "), maxHeaderText),
+			}})
+		case strings.HasPrefix(trimmed, "#"):
+			flush()
+			section = append(section, "*"+escape(strings.TrimSpace(strings.TrimLeft(trimmed, "#")))+"*")
+		case strings.HasPrefix(trimmed, "- "):
+			depth := (len(line) - len(strings.TrimLeft(line, " "))) / 2
+			bullet := "•"
+			if depth > 0 {
+				bullet = "◦"
+			}
+			item := escape(strings.TrimPrefix(trimmed, "- "))
+			section = append(section, strings.Repeat("    ", depth)+bullet+" "+hoursPattern.ReplaceAllString(item, "*$1*"))
+		default:
+			section = append(section, escape(trimmed))
+		}
+	}
+	flush()
+	return blocks
+}
+
+func splitText(lines []string, limit int) []string {
+	var out []string
+	var current strings.Builder
+	for _, line := range lines {
+		line = truncate(line, limit)
+		if current.Len() > 0 && current.Len()+1+len(line) > limit {
+			out = append(out, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		out = append(out, current.String())
+	}
+	return out
+}
+
+func fallbackText(blocks []Block) string {
+	for _, block := range blocks {
+		if block.Text != nil {
+			return truncate(block.Text.Text, maxFallbackText)
+		}
+	}
+	return ""
+}
+
+func truncate(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	for len(string(runes)) > limit-len("…") {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+func escape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
@@ -0,0 +1,105 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBlocksConvertsMarkdown(t *testing.T) {
+	markdown := "" +
+		"## 2026-01-10\n" +
+		"\n" +
+		"### タスク\n" +
+		"- Design <v2> 1.50h\n" +
+		"  - Mockups 0.50h\n" +
+		"\n" +
+		"### 合計\n" +
+		"- 1.50h ¥15,000\n"
+
+	blocks := Blocks(markdown)
+	if len(blocks) != 3 {
+		t.Fatalf("expected 3 blocks, got %d: %+v", len(blocks), blocks)
+	}
+	if blocks[0].Type != "header" || blocks[0].Text.Type != "plain_text" || blocks[0].Text.Text != "2026-01-10" {
+		t.Fatalf("unexpected header: %+v", blocks[0])
+	}
+	wantTask := "*タスク*\n• Design &lt;v2&gt; *1.50h*\n    ◦ Mockups *0.50h*"
+	if blocks[1].Type != "section" || blocks[1].Text.Type != "mrkdwn" || blocks[1].Text.Text != wantTask {
+		t.Fatalf("unexpected task section: %q", blocks[1].Text.Text)
+	}
+	if blocks[2].Text.Text != "*合計*\n• *1.50h* ¥15,000" {
+		t.Fatalf("unexpected total section: %q", blocks[2].Text.Text)
+	}
+}
+
+func TestMessagesSplitsLongOutput(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("### タスク\n")
+	for i := 0; i < 400; i++ {
+		b.WriteString("- A fairly long task description to fill the section 1.00h\n")
+	}
+	for i := 0; i < 60; i++ {
+		b.WriteString("\n## 2026-01-10\n")
+	}
+
+	messages := Messages(b.String())
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	total := 0
+	for _, message := range messages {
+		if len(message.Blocks) > maxBlocksPerMessage {
+			t.Fatalf("message has %d blocks", len(message.Blocks))
+		}
+		if message.Text == "" {
+			t.Fatalf("expected fallback text")
+		}
+		for _, block := range message.Blocks {
+			if block.Type == "section" {
+				if len(block.Text.Text) > maxSectionText {
+					t.Fatalf("section too long: %d", len(block.Text.Text))
+				}
+				total += strings.Count(block.Text.Text, "•")
+			}
+		}
+	}
+	if total != 400 {
+		t.Fatalf("expected all 400 bullets, got %d", total)
+	}
+}
+
+func TestClientPostsEachMessage(t *testing.T) {
+	var bodies []Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		var message Message
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Fatalf("invalid body: %v", err)
+		}
+		bodies = append(bodies, message)
+		if len(bodies) == 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, "invalid_blocks")
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, server.Client())
+	messages := []Message{{Text: "one"}, {Text: "two"}, {Text: "three"}}
+	err := client.Post(context.Background(), messages)
+	if err == nil || !strings.Contains(err.Error(), "slack message 2/3") || !strings.Contains(err.Error(), "invalid_blocks") {
+		t.Fatalf("expected error for second message, got %v", err)
+	}
+	if len(bodies) != 2 || bodies[0].Text != "one" {
+		t.Fatalf("unexpected bodies: %+v", bodies)
+	}
+}
@@ -0,0 +1,334 @@
+package summary
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultChartWidth = 80
+
+var (
+	barEighths  = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+	sparkLevels = []rune("▁▂▃▄▅▆▇█")
+)
+
+type chartRow struct {
+	Name   string
+	Total  time.Duration
+	Color  string
+	Series []time.Duration
+}
+
+func (row chartRow) total() time.Duration {
+	return row.Total
+}
+
+func (chartRow) fold(name string, rest []chartRow) chartRow {
+	other := chartRow{Name: name}
+	for _, row := range rest {
+		other.Total += row.Total
+		if row.Series == nil {
+			continue
+		}
+		if other.Series == nil {
+			other.Series = make([]time.Duration, len(row.Series))
+		}
+		for i, value := range row.Series {
+			other.Series[i] += value
+		}
+	}
+	return other
+}
+
+func FormatChart(buckets []Bucket, opts FormatOptions) string {
+	var b strings.Builder
+	if title := formatRangeTitle(opts); title != "" {
+		b.WriteString(title)
+		b.WriteString("\n\n")
+	}
+	if len(buckets) == 0 {
+		msg := strings.TrimSpace(opts.EmptyMessage)
+		if msg == "" {
+			msg = "No data"
+		}
+		b.WriteString(msg)
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	width := opts.Width
+	if width <= 0 {
+		width = defaultChartWidth
+	}
+
+	shares := projectShares(buckets)
+	projects := make([]chartRow, 0, len(shares))
+	colors := make(map[string]string, len(shares))
+	for _, share := range shares {
+		color := share.Color
+		if custom := strings.TrimSpace(opts.ProjectColors[share.Name]); custom != "" {
+			color = custom
+		}
+		colors[share.Name] = color
+		projects = append(projects, chartRow{Name: share.Name, Total: share.Total, Color: color})
+	}
+
+	var total time.Duration
+	taskTotals := map[string]time.Duration{}
+	for _, bucket := range buckets {
+		total += bucket.Total
+		for _, task := range bucket.Tasks {
+			taskTotals[task.Name] += task.Total
+		}
+	}
+	tasks := make([]chartRow, 0, len(taskTotals))
+	for name, taskTotal := range taskTotals {
+		tasks = append(tasks, chartRow{Name: name, Total: taskTotal})
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].Total == tasks[j].Total {
+			return tasks[i].Name < tasks[j].Name
+		}
+		return tasks[i].Total > tasks[j].Total
+	})
+
+	daily := buckets
+	if opts.DailyBuckets != nil {
+		daily = opts.DailyBuckets
+	}
+	days := chartDays(daily, opts)
+	totals := make([]time.Duration, len(days))
+	if len(days) > 1 {
+		index := make(map[string]int, len(projects))
+		for i := range projects {
+			projects[i].Series = make([]time.Duration, len(days))
+			index[projects[i].Name] = i
+		}
+		byDate := make(map[string]Bucket, len(daily))
+		for _, bucket := range daily {
+			byDate[bucket.Date] = bucket
+		}
+		for d, day := range days {
+			bucket := byDate[day]
+			totals[d] = bucket.Total
+			for _, project := range bucket.Projects {
+				if i, ok := index[project.Name]; ok {
+					projects[i].Series[d] += project.Total
+				}
+			}
+		}
+	}
+	projects = collapseTop(projects, opts.Top)
+
+	b.WriteString("プロジェクト\n")
+	writeBars(&b, projects, total, width, opts.Color)
+	b.WriteString("\nタスク\n")
+	writeBars(&b, collapseTop(tasks, opts.Top), total, width, opts.Color)
+
+	if len(days) > 1 {
+		b.WriteString("\n日別\n")
+		rows := append([]chartRow{{Name: "Total", Series: totals}}, projects...)
+		writeSparklines(&b, rows, days, width, opts.Color)
+	}
+
+	fmt.Fprintf(&b, "\nTotal %sh\n", FormatHours(total))
+	return b.String()
+}
+
+func writeBars(b *strings.Builder, rows []chartRow, total time.Duration, width int, color bool) {
+	var max time.Duration
+	labels := make([]string, len(rows))
+	labelWidth := 0
+	for i, row := range rows {
+		if row.Total > max {
+			max = row.Total
+		}
+		labels[i] = truncateLabel(row.Name, width/3)
+		if w := displayWidth(labels[i]); w > labelWidth {
+			labelWidth = w
+		}
+	}
+	barWidth := width - labelWidth - len("  ") - len("  0000.00h  100%")
+	if barWidth < 10 {
+		barWidth = 10
+	}
+	for i, row := range rows {
+		bar := ""
+		if max > 0 {
+			bar = renderBar(float64(row.Total)/float64(max)*float64(barWidth), barWidth)
+		}
+		padding := strings.Repeat(" ", barWidth-displayWidth(bar))
+		if color {
+			bar = colorize(bar, row.Color)
+		}
+		share := 0.0
+		if total > 0 {
+			share = 100 * float64(row.Total) / float64(total)
+		}
+		fmt.Fprintf(b, "%s%s  %s%s  %7sh  %3.0f%%\n", labels[i], strings.Repeat(" ", labelWidth-displayWidth(labels[i])), bar, padding, FormatHours(row.Total), share)
+	}
+}
+
+func renderBar(cells float64, limit int) string {
+	full := int(cells)
+	if full >= limit {
+		return strings.Repeat("█", limit)
+	}
+	bar := strings.Repeat("█", full) + barEighths[int((cells-float64(full))*8)]
+	if bar == "" && cells > 0 {
+		bar = barEighths[1]
+	}
+	return bar
+}
+
+func chartDays(buckets []Bucket, opts FormatOptions) []string {
+	if opts.RangeStart.IsZero() || opts.RangeEnd.IsZero() {
+		var days []string
+		for _, bucket := range buckets {
+			if bucket.Date != "" {
+				days = append(days, bucket.Date)
+			}
+		}
+		return days
+	}
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	var days []string
+	end := opts.RangeEnd.In(loc)
+	for date := opts.RangeStart.In(loc); date.Before(end); date = date.AddDate(0, 0, 1) {
+		days = append(days, date.Format(dateLayout))
+	}
+	return days
+}
+
+func writeSparklines(b *strings.Builder, rows []chartRow, days []string, width int, color bool) {
+	labelWidth := 0
+	labels := make([]string, len(rows))
+	for i, row := range rows {
+		labels[i] = truncateLabel(row.Name, width/3)
+		if w := displayWidth(labels[i]); w > labelWidth {
+			labelWidth = w
+		}
+	}
+	cells := width - labelWidth - len("  ") - len("  0000.00h")
+	if cells < 10 {
+		cells = 10
+	}
+	fmt.Fprintf(b, "%s  %s..%s\n", strings.Repeat(" ", labelWidth), days[0][5:], days[len(days)-1][5:])
+	for i, row := range rows {
+		line := sparkline(resample(row.Series, cells))
+		if color && i > 0 {
+			line = colorize(line, row.Color)
+		}
+		var sum time.Duration
+		for _, value := range row.Series {
+			sum += value
+		}
+		fmt.Fprintf(b, "%s%s  %s  %sh\n", labels[i], strings.Repeat(" ", labelWidth-displayWidth(labels[i])), line, FormatHours(sum))
+	}
+}
+
+func resample(values []time.Duration, cells int) []time.Duration {
+	if len(values) == 0 || cells <= 0 {
+		return values
+	}
+	out := make([]time.Duration, cells)
+	for i := range out {
+		start := i * len(values) / cells
+		end := max((i+1)*len(values)/cells, start+1)
+		var sum time.Duration
+		for _, value := range values[start:end] {
+			sum += value
+		}
+		out[i] = sum / time.Duration(end-start)
+	}
+	return out
+}
+
+func sparkline(values []time.Duration) string {
+	var max time.Duration
+	for _, value := range values {
+		if value > max {
+			max = value
+		}
+	}
+	out := make([]rune, len(values))
+	for i, value := range values {
+		if value <= 0 || max <= 0 {
+			out[i] = ' '
+			continue
+		}
+		level := int(float64(value) / float64(max) * float64(len(sparkLevels)-1))
+		out[i] = sparkLevels[level]
+	}
+	return string(out)
+}
+
+func colorize(text, hex string) string {
+	r, g, bl, ok := parseHexColor(hex)
+	if !ok || text == "" {
+		return text
+	}
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm%s\x1b[0m", r, g, bl, text)
+}
+
+func parseHexColor(hex string) (int64, int64, int64, bool) {
+	hex = strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if len(hex) != 6 {
+		return 0, 0, 0, false
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return int64(value >> 16 & 0xff), int64(value >> 8 & 0xff), int64(value & 0xff), true
+}
+
+func truncateLabel(name string, limit int) string {
+	if limit < 8 {
+		limit = 8
+	}
+	if displayWidth(name) <= limit {
+		return name
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range name {
+		w := runeWidth(r)
+		if width+w > limit-1 {
+			break
+		}
+		b.WriteRune(r)
+		width += w
+	}
+	b.WriteString("…")
+	return b.String()
+}
+
+func displayWidth(text string) int {
+	width := 0
+	for _, r := range text {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+func runeWidth(r rune) int {
+	switch {
+	case r >= 0x1100 && r <= 0x115f,
+		r >= 0x2e80 && r <= 0xa4cf,
+		r >= 0xac00 && r <= 0xd7a3,
+		r >= 0xf900 && r <= 0xfaff,
+		r >= 0xfe30 && r <= 0xfe4f,
+		r >= 0xff00 && r <= 0xff60,
+		r >= 0xffe0 && r <= 0xffe6:
+		return 2
+	default:
+		return 1
+	}
+}
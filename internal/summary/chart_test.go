@@ -0,0 +1,113 @@
+package summary
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatChartDrawsBarsAndSparklines(t *testing.T) {
+	buckets := []Bucket{
+		{
+			Date:     "2026-01-10",
+			Total:    3 * time.Hour,
+			Projects: []ProjectBucket{{Name: "Alpha", Total: 2 * time.Hour}, {Name: "Beta", Total: time.Hour}},
+			Tasks:    []TaskSummary{{Name: "Design", Total: 3 * time.Hour}},
+		},
+		{
+			Date:     "2026-01-12",
+			Total:    time.Hour,
+			Projects: []ProjectBucket{{Name: "Beta", Total: time.Hour}},
+			Tasks:    []TaskSummary{{Name: "Build", Total: time.Hour}},
+		},
+	}
+
+	got := FormatChart(buckets, FormatOptions{
+		Daily:      true,
+		RangeStart: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+		RangeEnd:   time.Date(2026, 1, 13, 0, 0, 0, 0, time.UTC),
+		Location:   time.UTC,
+		Width:      40,
+	})
+	want := "2026-01-10..2026-01-12\n\n" +
+		"プロジェクト\n" +
+		"Alpha  █████████████████     2.00h   50%\n" +
+		"Beta   █████████████████     2.00h   50%\n" +
+		"\nタスク\n" +
+		"Design  ████████████████     3.00h   75%\n" +
+		"Build   █████▎               1.00h   25%\n" +
+		"\n日別\n" +
+		"       01-10..01-12\n" +
+		"Total  ████████        ▃▃▃▃▃▃▃  4.00h\n" +
+		"Alpha  ████████                 2.00h\n" +
+		"Beta   ████████        ███████  2.00h\n" +
+		"\nTotal 4.00h\n"
+	if got != want {
+		t.Fatalf("unexpected chart:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatChartFoldsOtherSparklineAndFitsWidth(t *testing.T) {
+	var buckets []Bucket
+	for day := 1; day <= 31; day++ {
+		buckets = append(buckets, Bucket{
+			Date:     time.Date(2026, 1, day, 0, 0, 0, 0, time.UTC).Format(dateLayout),
+			Total:    3 * time.Hour,
+			Projects: []ProjectBucket{{Name: "Alpha", Total: 2 * time.Hour}, {Name: "Beta", Total: 30 * time.Minute}, {Name: "Gamma", Total: 30 * time.Minute}},
+		})
+	}
+	daily := FormatChart(buckets, FormatOptions{
+		RangeStart: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		RangeEnd:   time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		Location:   time.UTC,
+		Width:      40,
+		Top:        1,
+	})
+	summary := FormatChart([]Bucket{{Total: 93 * time.Hour, Projects: []ProjectBucket{{Name: "Alpha", Total: 62 * time.Hour}, {Name: "Beta", Total: 15*time.Hour + 30*time.Minute}, {Name: "Gamma", Total: 15*time.Hour + 30*time.Minute}}}}, FormatOptions{
+		RangeStart:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		RangeEnd:     time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		Location:     time.UTC,
+		Width:        40,
+		Top:          1,
+		DailyBuckets: buckets,
+	})
+	if summary != daily {
+		t.Fatalf("expected DailyBuckets to drive the sparklines:\n%s\nwant:\n%s", summary, daily)
+	}
+
+	section := daily[strings.Index(daily, "日別"):]
+	other := ""
+	for _, line := range strings.Split(section, "\n") {
+		if strings.HasPrefix(line, "Other (2") {
+			other = line
+		}
+		if displayWidth(line) > 40 {
+			t.Fatalf("expected sparkline rows to fit 40 columns, got %d: %q", displayWidth(line), line)
+		}
+	}
+	if !strings.HasSuffix(other, "31.00h") || !strings.Contains(other, "█") {
+		t.Fatalf("expected a filled Other sparkline, got %q in:\n%s", other, daily)
+	}
+}
+
+func TestFormatChartColorsBarsWithProjectColors(t *testing.T) {
+	buckets := []Bucket{{
+		Total:    time.Hour,
+		Projects: []ProjectBucket{{Name: "Alpha", Total: time.Hour}},
+		Tasks:    []TaskSummary{{Name: "Design", Total: time.Hour}},
+	}}
+
+	got := FormatChart(buckets, FormatOptions{Width: 40, Color: true, ProjectColors: map[string]string{"Alpha": "#ff8000"}})
+	if !strings.Contains(got, "Alpha  \x1b[38;2;255;128;0m█████████████████\x1b[0m     1.00h  100%\n") {
+		t.Fatalf("expected coloured project bar:\n%q", got)
+	}
+	if strings.Contains(got, "日別") {
+		t.Fatalf("expected no sparklines for a single bucket:\n%s", got)
+	}
+}
+
+func TestFormatChartTruncatesWideLabels(t *testing.T) {
+	if got := truncateLabel("データベース移行作業の続き", 10); got != "データベ…" || displayWidth(got) > 10 {
+		t.Fatalf("unexpected label: %q", got)
+	}
+}
@@ -0,0 +1,255 @@
+package summary
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+)
+
+type gridTable struct {
+	Title   string
+	Label   string
+	Days    []string
+	Rows    []gridRow
+	Totals  []time.Duration
+	Overall time.Duration
+}
+
+type gridRow struct {
+	Name  string
+	Cells []time.Duration
+	Total time.Duration
+}
+
+type htmlGrid struct {
+	Title   string
+	Label   string
+	Headers []string
+	Rows    []htmlGridRow
+	Totals  []string
+	Overall string
+}
+
+type htmlGridRow struct {
+	Name  string
+	Cells []string
+	Total string
+}
+
+var gridHTMLTemplate = template.Must(template.New("grid").Parse(`<!DOCTYPE html>
+<html lang="ja">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+tfoot th, td.total { font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+<thead>
+<tr><th>{{.Label}}</th>{{range .Headers}}<th>{{.}}</th>{{end}}<th>Total</th></tr>
+</thead>
+<tbody>
+{{- range .Rows}}
+<tr><td>{{.Name}}</td>{{range .Cells}}<td>{{.}}</td>{{end}}<td class="total">{{.Total}}</td></tr>
+{{- end}}
+</tbody>
+<tfoot>
+<tr><th>Total</th>{{range .Totals}}<th>{{.}}</th>{{end}}<th>{{.Overall}}</th></tr>
+</tfoot>
+</table>
+</body>
+</html>
+`))
+
+func FormatGrid(buckets []Bucket, opts FormatOptions) (string, error) {
+	table := buildGrid(buckets, opts)
+	switch strings.ToLower(strings.TrimSpace(opts.GridStyle)) {
+	case "", "markdown":
+		return formatGridMarkdown(table), nil
+	case "csv":
+		return formatGridCSV(table)
+	case "html":
+		return formatGridHTML(table)
+	default:
+		return "", fmt.Errorf("invalid grid style: %s", opts.GridStyle)
+	}
+}
+
+func buildGrid(buckets []Bucket, opts FormatOptions) gridTable {
+	table := gridTable{
+		Title: strings.TrimSpace("Toggl timesheet " + formatRangeTitle(opts)),
+		Label: "Project",
+		Days:  chartDays(buckets, opts),
+	}
+	byTask := strings.EqualFold(strings.TrimSpace(opts.GridRows), "task")
+	if byTask {
+		table.Label = "Task"
+	}
+	if len(table.Days) == 0 && len(buckets) > 0 {
+		table.Days = []string{""}
+	}
+	column := make(map[string]int, len(table.Days))
+	for i, day := range table.Days {
+		column[day] = i
+	}
+
+	rows := map[string]*gridRow{}
+	add := func(name string, day int, total time.Duration) {
+		row, ok := rows[name]
+		if !ok {
+			row = &gridRow{Name: name, Cells: make([]time.Duration, len(table.Days))}
+			rows[name] = row
+		}
+		row.Cells[day] += total
+		row.Total += total
+	}
+	table.Totals = make([]time.Duration, len(table.Days))
+	for _, bucket := range buckets {
+		day, ok := column[bucket.Date]
+		if !ok {
+			continue
+		}
+		table.Totals[day] += bucket.Total
+		table.Overall += bucket.Total
+		if byTask {
+			for _, task := range bucket.Tasks {
+				add(task.Name, day, task.Total)
+			}
+			continue
+		}
+		for _, project := range bucket.Projects {
+			add(project.Name, day, project.Total)
+		}
+	}
+
+	for _, row := range rows {
+		table.Rows = append(table.Rows, *row)
+	}
+	sort.Slice(table.Rows, func(i, j int) bool {
+		if table.Rows[i].Total == table.Rows[j].Total {
+			return table.Rows[i].Name < table.Rows[j].Name
+		}
+		return table.Rows[i].Total > table.Rows[j].Total
+	})
+	return table
+}
+
+func gridHeader(day string) string {
+	date, err := time.Parse(dateLayout, day)
+	if err != nil {
+		return "Total"
+	}
+	return date.Format("01-02 Mon")
+}
+
+func gridCell(total time.Duration) string {
+	if total <= 0 {
+		return "-"
+	}
+	return FormatHours(total)
+}
+
+func formatGridMarkdown(table gridTable) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", table.Title)
+	if len(table.Rows) == 0 {
+		b.WriteString("No data\n")
+		return b.String()
+	}
+	cells := []string{table.Label}
+	align := []string{"---"}
+	for _, day := range table.Days {
+		cells = append(cells, gridHeader(day))
+		align = append(align, "---:")
+	}
+	cells = append(cells, "Total")
+	align = append(align, "---:")
+	writeMarkdownRow(&b, cells)
+	writeMarkdownRow(&b, align)
+	for _, row := range table.Rows {
+		cells := []string{strings.ReplaceAll(row.Name, "|", `\|`)}
+		for _, cell := range row.Cells {
+			cells = append(cells, gridCell(cell))
+		}
+		writeMarkdownRow(&b, append(cells, FormatHours(row.Total)))
+	}
+	cells = []string{"**Total**"}
+	for _, total := range table.Totals {
+		cells = append(cells, "**"+FormatHours(total)+"**")
+	}
+	writeMarkdownRow(&b, append(cells, "**"+FormatHours(table.Overall)+"**"))
+	return b.String()
+}
+
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	b.WriteString("| ")
+	b.WriteString(strings.Join(cells, " | "))
+	b.WriteString(" |\n")
+}
+
+func formatGridCSV(table gridTable) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := []string{table.Label}
+	for _, day := range table.Days {
+		if day == "" {
+			day = "Total"
+		}
+		header = append(header, day)
+	}
+	records := [][]string{append(header, "Total")}
+	for _, row := range table.Rows {
+		record := []string{row.Name}
+		for _, cell := range row.Cells {
+			record = append(record, FormatHours(cell))
+		}
+		records = append(records, append(record, FormatHours(row.Total)))
+	}
+	totals := []string{"Total"}
+	for _, total := range table.Totals {
+		totals = append(totals, FormatHours(total))
+	}
+	records = append(records, append(totals, FormatHours(table.Overall)))
+	if err := w.WriteAll(records); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func formatGridHTML(table gridTable) (string, error) {
+	page := htmlGrid{
+		Title:   table.Title,
+		Label:   table.Label,
+		Overall: FormatHours(table.Overall),
+	}
+	for _, day := range table.Days {
+		page.Headers = append(page.Headers, gridHeader(day))
+	}
+	for _, row := range table.Rows {
+		out := htmlGridRow{Name: row.Name, Total: FormatHours(row.Total)}
+		for _, cell := range row.Cells {
+			out.Cells = append(out.Cells, gridCell(cell))
+		}
+		page.Rows = append(page.Rows, out)
+	}
+	for _, total := range table.Totals {
+		page.Totals = append(page.Totals, FormatHours(total))
+	}
+
+	var buf bytes.Buffer
+	if err := gridHTMLTemplate.Execute(&buf, page); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,87 @@
+package summary
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func gridTestBuckets() []Bucket {
+	return []Bucket{
+		{
+			Date:     "2026-01-10",
+			Total:    3 * time.Hour,
+			Projects: []ProjectBucket{{Name: "Alpha", Total: 2 * time.Hour}, {Name: "Beta|Ops", Total: time.Hour}},
+			Tasks:    []TaskSummary{{Name: "Design", Total: 3 * time.Hour}},
+		},
+		{
+			Date:     "2026-01-12",
+			Total:    90 * time.Minute,
+			Projects: []ProjectBucket{{Name: "Beta|Ops", Total: 90 * time.Minute}},
+			Tasks:    []TaskSummary{{Name: "Build", Total: 90 * time.Minute}},
+		},
+	}
+}
+
+func gridTestOptions(style, rows string) FormatOptions {
+	return FormatOptions{
+		Daily:      true,
+		RangeStart: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+		RangeEnd:   time.Date(2026, 1, 13, 0, 0, 0, 0, time.UTC),
+		Location:   time.UTC,
+		GridStyle:  style,
+		GridRows:   rows,
+	}
+}
+
+func TestFormatGridMarkdown(t *testing.T) {
+	got, err := FormatGrid(gridTestBuckets(), gridTestOptions("markdown", "project"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "## Toggl timesheet 2026-01-10..2026-01-12\n\n" +
+		"| Project | 01-10 Sat | 01-11 Sun | 01-12 Mon | Total |\n" +
+		"| --- | ---: | ---: | ---: | ---: |\n" +
+		"| Beta\\|Ops | 1.00 | - | 1.50 | 2.50 |\n" +
+		"| Alpha | 2.00 | - | - | 2.00 |\n" +
+		"| **Total** | **3.00** | **0.00** | **1.50** | **4.50** |\n"
+	if got != want {
+		t.Fatalf("unexpected grid:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatGridCSVByTask(t *testing.T) {
+	got, err := FormatGrid(gridTestBuckets(), gridTestOptions("csv", "task"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Task,2026-01-10,2026-01-11,2026-01-12,Total\n" +
+		"Design,3.00,0.00,0.00,3.00\n" +
+		"Build,0.00,0.00,1.50,1.50\n" +
+		"Total,3.00,0.00,1.50,4.50\n"
+	if got != want {
+		t.Fatalf("unexpected csv:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatGridHTML(t *testing.T) {
+	got, err := FormatGrid(gridTestBuckets(), gridTestOptions("html", ""))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"<tr><th>Project</th><th>01-10 Sat</th><th>01-11 Sun</th><th>01-12 Mon</th><th>Total</th></tr>",
+		"<tr><td>Beta|Ops</td><td>1.00</td><td>-</td><td>1.50</td><td class=\"total\">2.50</td></tr>",
+		"<tr><th>Total</th><th>3.00</th><th>0.00</th><th>1.50</th><th>4.50</th></tr>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output:\n%s", want, got)
+		}
+	}
+}
+
+func TestFormatGridInvalidStyle(t *testing.T) {
+	if _, err := FormatGrid(nil, FormatOptions{GridStyle: "xlsx"}); err == nil {
+		t.Fatal("expected error for unknown grid style")
+	}
+}
@@ -0,0 +1,221 @@
+package summary
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+type htmlPage struct {
+	Title    string
+	Empty    string
+	Currency string
+	Bars     template.HTML
+	Pie      template.HTML
+	Legend   []htmlLegend
+	Sections []htmlSection
+	Total    htmlRow
+}
+
+type htmlLegend struct {
+	Name  string
+	Hours string
+	Share string
+	Color template.CSS
+}
+
+type htmlSection struct {
+	Heading string
+	Tables  []htmlTable
+}
+
+type htmlTable struct {
+	Heading string
+	Rows    []htmlRow
+}
+
+type htmlRow struct {
+	Name     string
+	Hours    string
+	Earnings string
+	Note     string
+}
+
+var summaryHTMLTemplate = template.Must(template.New("summary").Parse(`<!DOCTYPE html>
+<html lang="ja">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; min-width: 24em; margin-bottom: 1.5em; }
+th, td { border-bottom: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+td.num, th.num { text-align: right; }
+td.note { color: #666; }
+.charts { display: flex; flex-wrap: wrap; gap: 2em; align-items: flex-start; margin-bottom: 1.5em; }
+.legend td { border: none; padding: 0.1em 0.4em; }
+.swatch { display: inline-block; width: 0.8em; height: 0.8em; margin-right: 0.4em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if .Empty}}
+<p>{{.Empty}}</p>
+{{- end}}
+{{- if .Legend}}
+<div class="charts">
+{{.Bars}}
+{{.Pie}}
+<table class="legend">
+{{- range .Legend}}
+<tr><td><span class="swatch" style="background: {{.Color}}"></span>{{.Name}}</td><td class="num">{{.Hours}}</td><td class="num">{{.Share}}</td></tr>
+{{- end}}
+</table>
+</div>
+{{- end}}
+{{- range .Sections}}
+{{- if .Heading}}
+<h2>{{.Heading}}</h2>
+{{- end}}
+{{- range .Tables}}
+<h3>{{.Heading}}</h3>
+<table>
+{{- range .Rows}}
+<tr><td>{{.Name}}</td><td class="num">{{.Hours}}</td>{{if $.Currency}}<td class="num">{{.Earnings}}</td>{{end}}{{if .Note}}<td class="note">{{.Note}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+{{- if not .Empty}}
+<h2>合計</h2>
+<table>
+<tr><th>{{.Total.Name}}</th><th class="num">{{.Total.Hours}}</th>{{if .Currency}}<th class="num">{{.Total.Earnings}}</th>{{end}}</tr>
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+func FormatHTML(buckets []Bucket, opts FormatOptions) (string, error) {
+	page := htmlPage{
+		Title:    strings.TrimSpace("Toggl summary " + formatRangeTitle(opts)),
+		Currency: opts.Currency,
+	}
+	if len(buckets) == 0 {
+		page.Empty = opts.EmptyMessage
+		if page.Empty == "" {
+			page.Empty = "No data"
+		}
+	}
+
+	shares := projectShares(buckets)
+	if len(shares) > 0 {
+		bars := buckets
+		if opts.DailyBuckets != nil {
+			bars = opts.DailyBuckets
+		}
+		page.Bars = template.HTML(svgStackedBars(bars, shares))
+		page.Pie = template.HTML(svgPie(shares))
+	}
+
+	var total time.Duration
+	var earnings float64
+	for _, bucket := range buckets {
+		total += bucket.Total
+		earnings += bucket.Earnings
+		section := htmlSection{Heading: bucket.Date}
+
+		groups := bucket.Users
+		if len(groups) == 0 {
+			groups = bucket.Workspaces
+		}
+		if len(groups) > 0 {
+			for _, group := range groups {
+				section.Tables = append(section.Tables, htmlTable{
+					Heading: fmt.Sprintf("%s %sh", group.Name, FormatHours(group.Total)),
+					Rows:    htmlProjectRows(group.Projects, opts),
+				})
+			}
+			page.Sections = append(page.Sections, section)
+			continue
+		}
+
+		tasks := make([]lineItem, 0, len(bucket.Tasks))
+		for _, task := range bucket.Tasks {
+			tasks = append(tasks, lineItem{Name: task.Name, Total: task.Total, Earnings: task.Earnings})
+		}
+		section.Tables = append(section.Tables,
+			htmlTable{Heading: "タスク", Rows: htmlRows(collapseTop(tasks, opts.Top), opts)},
+			htmlTable{Heading: "プロジェクト", Rows: htmlProjectRows(bucket.Projects, opts)},
+		)
+		if opts.ShowBillable {
+			section.Tables = append(section.Tables, htmlTable{
+				Heading: "請求区分",
+				Rows: []htmlRow{
+					{Name: "Billable", Hours: FormatHours(bucket.Billable) + "h"},
+					{Name: "Non-billable", Hours: FormatHours(bucket.NonBillable) + "h"},
+				},
+			})
+		}
+		page.Sections = append(page.Sections, section)
+	}
+	for _, share := range shares {
+		legend := htmlLegend{Name: share.Name, Hours: FormatHours(share.Total) + "h", Color: template.CSS(share.Color)}
+		if total > 0 {
+			legend.Share = fmt.Sprintf("%.0f%%", 100*float64(share.Total)/float64(total))
+		}
+		page.Legend = append(page.Legend, legend)
+	}
+	page.Total = htmlRow{Name: "Total", Hours: FormatHours(total) + "h"}
+	if opts.Currency != "" {
+		page.Total.Earnings = FormatMoney(earnings, opts.Currency)
+	}
+
+	var buf bytes.Buffer
+	if err := summaryHTMLTemplate.Execute(&buf, page); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func htmlProjectRows(projects []ProjectBucket, opts FormatOptions) []htmlRow {
+	items := make([]lineItem, 0, len(projects))
+	for _, project := range projects {
+		item := lineItem{Name: project.Name, Total: project.Total, Earnings: project.Earnings}
+		if opts.ShowBillable {
+			item.Suffix = fmt.Sprintf("billable %sh / non-billable %sh", FormatHours(project.Billable), FormatHours(project.NonBillable))
+		}
+		items = append(items, item)
+	}
+	return htmlRows(collapseTop(items, opts.Top), opts)
+}
+
+func htmlRows(items []lineItem, opts FormatOptions) []htmlRow {
+	rows := make([]htmlRow, 0, len(items))
+	for _, item := range items {
+		row := htmlRow{Name: item.Name, Hours: FormatHours(item.Total) + "h", Note: item.Suffix}
+		if opts.Currency != "" {
+			row.Earnings = FormatMoney(item.Earnings, opts.Currency)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func formatRangeTitle(opts FormatOptions) string {
+	if opts.RangeStart.IsZero() || opts.RangeEnd.IsZero() {
+		return ""
+	}
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	start := opts.RangeStart.In(loc)
+	end := opts.RangeEnd.In(loc).AddDate(0, 0, -1)
+	if !end.After(start) {
+		return start.Format(dateLayout)
+	}
+	return fmt.Sprintf("%s..%s", start.Format(dateLayout), end.Format(dateLayout))
+}
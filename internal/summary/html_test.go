@@ -0,0 +1,95 @@
+package summary
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatHTMLRendersTables(t *testing.T) {
+	buckets := []Bucket{
+		{
+			Date:     "2026-01-10",
+			Total:    90 * time.Minute,
+			Earnings: 15000,
+			Projects: []ProjectBucket{{Name: "Alpha & Beta", Total: 90 * time.Minute, Earnings: 15000}},
+			Tasks:    []TaskSummary{{Name: "<Design>", Total: 90 * time.Minute, Earnings: 15000}},
+		},
+	}
+
+	got, err := FormatHTML(buckets, FormatOptions{
+		RangeStart: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+		RangeEnd:   time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC),
+		Location:   time.UTC,
+		Currency:   "JPY",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"<title>Toggl summary 2026-01-10</title>",
+		"<h2>2026-01-10</h2>",
+		"<tr><td>&lt;Design&gt;</td><td class=\"num\">1.50h</td><td class=\"num\">JPY 15000.00</td></tr>",
+		"<tr><td>Alpha &amp; Beta</td><td class=\"num\">1.50h</td><td class=\"num\">JPY 15000.00</td></tr>",
+		"<tr><th>Total</th><th class=\"num\">1.50h</th><th class=\"num\">JPY 15000.00</th></tr>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output:\n%s", want, got)
+		}
+	}
+}
+
+func TestFormatHTMLEmpty(t *testing.T) {
+	got, err := FormatHTML(nil, FormatOptions{
+		RangeStart:   time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+		RangeEnd:     time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC),
+		Location:     time.UTC,
+		EmptyMessage: "No data",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(got, "<h1>Toggl summary 2026-01-05..2026-01-11</h1>") || !strings.Contains(got, "<p>No data</p>") || strings.Contains(got, "合計") {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
+
+func TestFormatHTMLRendersCharts(t *testing.T) {
+	buckets := []Bucket{
+		{
+			Date:     "2026-01-10",
+			Total:    3 * time.Hour,
+			Projects: []ProjectBucket{{Name: "Alpha", Total: 2 * time.Hour}, {Name: "Beta", Total: time.Hour}},
+		},
+		{
+			Date:     "2026-01-11",
+			Total:    time.Hour,
+			Projects: []ProjectBucket{{Name: "Beta", Total: time.Hour}},
+		},
+	}
+
+	got, err := FormatHTML(buckets, FormatOptions{Daily: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		`class="bars"`,
+		`<text x="116.0" y="226" font-size="11" text-anchor="middle">01-11</text>`,
+		`<title>2026-01-10 Alpha 2.00h</title>`,
+		`class="pie"`,
+		`<title>Alpha 2.00h (50%)</title>`,
+		`<tr><td><span class="swatch" style="background: #06aaf5"></span>Alpha</td><td class="num">2.00h</td><td class="num">50%</td></tr>`,
+		`<tr><td><span class="swatch" style="background: #c56bff"></span>Beta</td><td class="num">2.00h</td><td class="num">50%</td></tr>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output:\n%s", want, got)
+		}
+	}
+}
+
+func TestSVGPieDrawsFullCircleForSingleProject(t *testing.T) {
+	got := svgPie([]chartSlice{{Name: "Alpha", Total: time.Hour, Color: "#06aaf5"}})
+	if !strings.Contains(got, `<circle cx="100" cy="100" r="90" fill="#06aaf5">`) || strings.Contains(got, "<path") {
+		t.Fatalf("unexpected pie:\n%s", got)
+	}
+}
@@ -11,48 +11,92 @@ import (
 const dateLayout = "2006-01-02"
 
 type Entry struct {
-	Project  string
-	Task     string
-	Start    time.Time
-	Duration time.Duration
+	Workspace string
+	User      string
+	Project   string
+	Task      string
+	Start     time.Time
+	Duration  time.Duration
+	Billable  bool
+	Rate      float64
 }
 
 type TaskBucket struct {
-	Name  string
-	Total time.Duration
+	Name     string
+	Total    time.Duration
+	Earnings float64
 }
 
 type TaskSummary struct {
 	Name       string
 	Total      time.Duration
+	Earnings   float64
 	FirstStart time.Time
 }
 
+type TaskNode struct {
+	Name     string
+	Total    time.Duration
+	Earnings float64
+	Children []TaskNode
+}
+
 type ProjectBucket struct {
-	Name  string
-	Total time.Duration
-	Tasks []TaskBucket
+	Name        string
+	Total       time.Duration
+	Billable    time.Duration
+	NonBillable time.Duration
+	Earnings    float64
+	Tasks       []TaskBucket
+	Tree        []TaskNode
+}
+
+type GroupBucket struct {
+	Name        string
+	Total       time.Duration
+	Billable    time.Duration
+	NonBillable time.Duration
+	Earnings    float64
+	Projects    []ProjectBucket
 }
 
 type Bucket struct {
-	Date     string
-	Projects []ProjectBucket
-	Tasks    []TaskSummary
+	Date        string
+	Total       time.Duration
+	Billable    time.Duration
+	NonBillable time.Duration
+	Earnings    float64
+	Projects    []ProjectBucket
+	Tasks       []TaskSummary
+	Users       []GroupBucket
+	Workspaces  []GroupBucket
 }
 
 type FormatOptions struct {
-	Daily        bool
-	RangeStart   time.Time
-	RangeEnd     time.Time
-	Location     *time.Location
-	Format       string
-	EmptyMessage string
+	Daily         bool
+	RangeStart    time.Time
+	RangeEnd      time.Time
+	Location      *time.Location
+	Format        string
+	EmptyMessage  string
+	Top           int
+	ShowBillable  bool
+	Currency      string
+	Width         int
+	Color         bool
+	ProjectColors map[string]string
+	GridRows      string
+	GridStyle     string
+	DailyBuckets  []Bucket
 }
 
 type AggregateOptions struct {
 	Daily                  bool
 	Location               *time.Location
 	SeparateTasksByProject bool
+	TaskDelimiter          string
+	GroupByUser            bool
+	GroupByWorkspace       bool
 }
 
 func Aggregate(entries []Entry, opts AggregateOptions) []Bucket {
@@ -64,15 +108,25 @@ func Aggregate(entries []Entry, opts AggregateOptions) []Bucket {
 	daily := opts.Daily
 	separateTasks := opts.SeparateTasksByProject
 
-	type taskMap map[string]time.Duration
-	type projectMap map[string]taskMap
+	type taskTotal struct {
+		total    time.Duration
+		earnings float64
+	}
+	type projectAgg struct {
+		tasks    map[string]*taskTotal
+		billable time.Duration
+	}
+	type projectMap map[string]*projectAgg
 	type taskAgg struct {
 		total      time.Duration
+		earnings   float64
 		firstStart time.Time
 	}
 
 	grouped := map[string]projectMap{}
 	taskGroups := map[string]map[string]*taskAgg{}
+	userEntries := map[string]map[string][]Entry{}
+	workspaceEntries := map[string]map[string][]Entry{}
 
 	for _, entry := range entries {
 		dateKey := ""
@@ -85,26 +139,52 @@ func Aggregate(entries []Entry, opts AggregateOptions) []Bucket {
 		if separateTasks {
 			taskKey = fmt.Sprintf("%s / %s", projectName, taskName)
 		}
+		earnings := entryEarnings(entry)
+		if opts.GroupByUser {
+			userName := normalizeUser(entry.User)
+			if _, ok := userEntries[dateKey]; !ok {
+				userEntries[dateKey] = map[string][]Entry{}
+			}
+			userEntries[dateKey][userName] = append(userEntries[dateKey][userName], entry)
+		}
+		if opts.GroupByWorkspace {
+			workspaceName := normalizeWorkspace(entry.Workspace)
+			if _, ok := workspaceEntries[dateKey]; !ok {
+				workspaceEntries[dateKey] = map[string][]Entry{}
+			}
+			workspaceEntries[dateKey][workspaceName] = append(workspaceEntries[dateKey][workspaceName], entry)
+		}
 
 		if _, ok := grouped[dateKey]; !ok {
 			grouped[dateKey] = projectMap{}
 		}
-		if _, ok := grouped[dateKey][projectName]; !ok {
-			grouped[dateKey][projectName] = taskMap{}
+		project, ok := grouped[dateKey][projectName]
+		if !ok {
+			project = &projectAgg{tasks: map[string]*taskTotal{}}
+			grouped[dateKey][projectName] = project
+		}
+		if _, ok := project.tasks[taskName]; !ok {
+			project.tasks[taskName] = &taskTotal{}
+		}
+		project.tasks[taskName].total += entry.Duration
+		project.tasks[taskName].earnings += earnings
+		if entry.Billable {
+			project.billable += entry.Duration
 		}
-		grouped[dateKey][projectName][taskName] += entry.Duration
 
 		if _, ok := taskGroups[dateKey]; !ok {
 			taskGroups[dateKey] = map[string]*taskAgg{}
 		}
 		if agg, ok := taskGroups[dateKey][taskKey]; ok {
 			agg.total += entry.Duration
+			agg.earnings += earnings
 			if entry.Start.Before(agg.firstStart) {
 				agg.firstStart = entry.Start
 			}
 		} else {
 			taskGroups[dateKey][taskKey] = &taskAgg{
 				total:      entry.Duration,
+				earnings:   earnings,
 				firstStart: entry.Start,
 			}
 		}
@@ -120,29 +200,41 @@ func Aggregate(entries []Entry, opts AggregateOptions) []Bucket {
 	for _, dateKey := range dateKeys {
 		projects := grouped[dateKey]
 		projectBuckets := make([]ProjectBucket, 0, len(projects))
-		for projectName := range projects {
-			tasks := projects[projectName]
-			taskNames := make([]string, 0, len(tasks))
-			for name := range tasks {
+		var bucketTotal, bucketBillable time.Duration
+		var bucketEarnings float64
+		for projectName, project := range projects {
+			taskNames := make([]string, 0, len(project.tasks))
+			for name := range project.tasks {
 				taskNames = append(taskNames, name)
 			}
 			sort.Strings(taskNames)
 
 			taskBuckets := make([]TaskBucket, 0, len(taskNames))
 			var projectTotal time.Duration
+			var projectEarnings float64
 			for _, taskName := range taskNames {
-				total := tasks[taskName]
-				projectTotal += total
+				task := project.tasks[taskName]
+				projectTotal += task.total
+				projectEarnings += task.earnings
 				taskBuckets = append(taskBuckets, TaskBucket{
-					Name:  taskName,
-					Total: total,
+					Name:     taskName,
+					Total:    task.total,
+					Earnings: task.earnings,
 				})
 			}
 
+			bucketTotal += projectTotal
+			bucketBillable += project.billable
+			bucketEarnings += projectEarnings
+
 			projectBuckets = append(projectBuckets, ProjectBucket{
-				Name:  projectName,
-				Total: projectTotal,
-				Tasks: taskBuckets,
+				Name:        projectName,
+				Total:       projectTotal,
+				Billable:    project.billable,
+				NonBillable: projectTotal - project.billable,
+				Earnings:    projectEarnings,
+				Tasks:       taskBuckets,
+				Tree:        buildTaskTree(taskBuckets, opts.TaskDelimiter),
 			})
 		}
 
@@ -159,6 +251,7 @@ func Aggregate(entries []Entry, opts AggregateOptions) []Bucket {
 			taskSummaries = append(taskSummaries, TaskSummary{
 				Name:       name,
 				Total:      agg.total,
+				Earnings:   agg.earnings,
 				FirstStart: agg.firstStart,
 			})
 		}
@@ -170,29 +263,127 @@ func Aggregate(entries []Entry, opts AggregateOptions) []Bucket {
 		})
 
 		buckets = append(buckets, Bucket{
-			Date:     dateKey,
-			Projects: projectBuckets,
-			Tasks:    taskSummaries,
+			Date:        dateKey,
+			Total:       bucketTotal,
+			Billable:    bucketBillable,
+			NonBillable: bucketTotal - bucketBillable,
+			Earnings:    bucketEarnings,
+			Projects:    projectBuckets,
+			Tasks:       taskSummaries,
+			Users:       aggregateGroups(userEntries[dateKey], opts),
+			Workspaces:  aggregateGroups(workspaceEntries[dateKey], opts),
 		})
 	}
 
 	return buckets
 }
 
+func aggregateGroups(entriesByGroup map[string][]Entry, opts AggregateOptions) []GroupBucket {
+	if len(entriesByGroup) == 0 {
+		return nil
+	}
+	groupOpts := opts
+	groupOpts.Daily = false
+	groupOpts.GroupByUser = false
+	groupOpts.GroupByWorkspace = false
+
+	groups := make([]GroupBucket, 0, len(entriesByGroup))
+	for name, entries := range entriesByGroup {
+		buckets := Aggregate(entries, groupOpts)
+		if len(buckets) == 0 {
+			continue
+		}
+		groups = append(groups, GroupBucket{
+			Name:        name,
+			Total:       buckets[0].Total,
+			Billable:    buckets[0].Billable,
+			NonBillable: buckets[0].NonBillable,
+			Earnings:    buckets[0].Earnings,
+			Projects:    buckets[0].Projects,
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Total == groups[j].Total {
+			return groups[i].Name < groups[j].Name
+		}
+		return groups[i].Total > groups[j].Total
+	})
+	return groups
+}
+
+func entryEarnings(entry Entry) float64 {
+	if entry.Rate <= 0 {
+		return 0
+	}
+	return entry.Duration.Hours() * entry.Rate
+}
+
 func FormatMarkdown(buckets []Bucket, opts FormatOptions) string {
 	var b strings.Builder
 	if len(buckets) == 0 {
 		return formatEmpty(&b, opts)
 	}
 	format := normalizeFormat(opts.Format)
+	if hasGroups(buckets, usersOf) {
+		return formatGroups(&b, buckets, opts, format == "detail", usersOf)
+	}
+	if hasGroups(buckets, workspacesOf) {
+		return formatGroups(&b, buckets, opts, format == "detail", workspacesOf)
+	}
 	switch format {
 	case "detail":
-		return formatDetail(&b, buckets)
+		return formatDetail(&b, buckets, opts)
 	default:
 		return formatDefault(&b, buckets, opts)
 	}
 }
 
+func usersOf(bucket Bucket) []GroupBucket {
+	return bucket.Users
+}
+
+func workspacesOf(bucket Bucket) []GroupBucket {
+	return bucket.Workspaces
+}
+
+func hasGroups(buckets []Bucket, groupsOf func(Bucket) []GroupBucket) bool {
+	for _, bucket := range buckets {
+		if len(groupsOf(bucket)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func formatGroups(b *strings.Builder, buckets []Bucket, opts FormatOptions, detail bool, groupsOf func(Bucket) []GroupBucket) string {
+	for i, bucket := range buckets {
+		if bucket.Date != "" {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(b, "## %s\n", bucket.Date)
+			b.WriteString("\n")
+		}
+		for j, group := range groupsOf(bucket) {
+			if j > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(b, "### %s\n", formatLine(lineItem{Name: group.Name, Total: group.Total, Earnings: group.Earnings}, opts))
+			if detail {
+				writeProjectDetails(b, group.Projects, opts, "####", true)
+			} else {
+				writeProjectList(b, group.Projects, opts)
+			}
+			if opts.ShowBillable {
+				b.WriteString("\n")
+				writeBillableSection(b, "####", group.Billable, group.NonBillable)
+			}
+		}
+	}
+	writeRangeTotal(b, buckets, opts)
+	return b.String()
+}
+
 func formatEmpty(b *strings.Builder, opts FormatOptions) string {
 	format := normalizeFormat(opts.Format)
 	if format == "default" {
@@ -242,7 +433,7 @@ func formatEmpty(b *strings.Builder, opts FormatOptions) string {
 	return b.String()
 }
 
-func formatDetail(b *strings.Builder, buckets []Bucket) string {
+func formatDetail(b *strings.Builder, buckets []Bucket, opts FormatOptions) string {
 	emitGap := false
 	for _, bucket := range buckets {
 		if bucket.Date != "" {
@@ -252,16 +443,13 @@ func formatDetail(b *strings.Builder, buckets []Bucket) string {
 			fmt.Fprintf(b, "## %s\n", bucket.Date)
 			emitGap = true
 		}
-		for i, project := range bucket.Projects {
-			if i > 0 || bucket.Date != "" {
-				b.WriteString("\n")
-			}
-			fmt.Fprintf(b, "### %s %sh\n", project.Name, formatHours(project.Total))
-			for _, task := range project.Tasks {
-				fmt.Fprintf(b, "- %s %sh\n", task.Name, formatHours(task.Total))
-			}
+		writeProjectDetails(b, bucket.Projects, opts, "###", bucket.Date != "")
+		if opts.ShowBillable {
+			b.WriteString("\n")
+			writeBillableSection(b, "###", bucket.Billable, bucket.NonBillable)
 		}
 	}
+	writeRangeTotal(b, buckets, opts)
 	return b.String()
 }
 
@@ -276,20 +464,68 @@ func formatDefault(b *strings.Builder, buckets []Bucket, opts FormatOptions) str
 		}
 
 		b.WriteString("### タスク\n")
+		taskItems := make([]lineItem, 0, len(bucket.Tasks))
 		for _, task := range bucket.Tasks {
-			fmt.Fprintf(b, "- %s %sh\n", task.Name, formatHours(task.Total))
+			taskItems = append(taskItems, lineItem{Name: task.Name, Total: task.Total, Earnings: task.Earnings})
+		}
+		for _, item := range collapseTop(taskItems, opts.Top) {
+			fmt.Fprintf(b, "- %s\n", formatLine(item, opts))
 		}
 
 		b.WriteString("\n")
 
 		b.WriteString("### プロジェクト\n")
-		for _, project := range bucket.Projects {
-			fmt.Fprintf(b, "- %s %sh\n", project.Name, formatHours(project.Total))
+		writeProjectList(b, bucket.Projects, opts)
+
+		if opts.ShowBillable {
+			b.WriteString("\n")
+			writeBillableSection(b, "###", bucket.Billable, bucket.NonBillable)
 		}
 	}
+	writeRangeTotal(b, buckets, opts)
 	return b.String()
 }
 
+func writeProjectDetails(b *strings.Builder, projects []ProjectBucket, opts FormatOptions, heading string, leadingGap bool) {
+	for i, project := range collapseTop(projects, opts.Top) {
+		if i > 0 || leadingGap {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(b, "%s %s\n", heading, formatLine(lineItem{
+			Name:     project.Name,
+			Total:    project.Total,
+			Earnings: project.Earnings,
+			Suffix:   billableSuffix(project, opts),
+		}, opts))
+		if len(project.Tree) > 0 {
+			writeTaskTree(b, project.Tree, 0, opts)
+			continue
+		}
+		items := make([]lineItem, 0, len(project.Tasks))
+		for _, task := range project.Tasks {
+			items = append(items, lineItem{Name: task.Name, Total: task.Total, Earnings: task.Earnings})
+		}
+		for _, item := range collapseTop(items, opts.Top) {
+			fmt.Fprintf(b, "- %s\n", formatLine(item, opts))
+		}
+	}
+}
+
+func writeProjectList(b *strings.Builder, projects []ProjectBucket, opts FormatOptions) {
+	items := make([]lineItem, 0, len(projects))
+	for _, project := range projects {
+		items = append(items, lineItem{
+			Name:     project.Name,
+			Total:    project.Total,
+			Earnings: project.Earnings,
+			Suffix:   billableSuffix(project, opts),
+		})
+	}
+	for _, item := range collapseTop(items, opts.Top) {
+		fmt.Fprintf(b, "- %s\n", formatLine(item, opts))
+	}
+}
+
 func formatDefaultEmptyDaily(b *strings.Builder, opts FormatOptions, msg string) string {
 	loc := opts.Location
 	if loc == nil {
@@ -311,18 +547,232 @@ func formatDefaultEmptyDaily(b *strings.Builder, opts FormatOptions, msg string)
 	return b.String()
 }
 
+func writeTaskTree(b *strings.Builder, nodes []TaskNode, depth int, opts FormatOptions) {
+	items := make([]lineItem, 0, len(nodes))
+	for _, node := range nodes {
+		items = append(items, lineItem{
+			Name:     node.Name,
+			Total:    node.Total,
+			Earnings: node.Earnings,
+			Children: node.Children,
+		})
+	}
+	indent := strings.Repeat("  ", depth)
+	for _, item := range collapseTop(items, opts.Top) {
+		fmt.Fprintf(b, "%s- %s\n", indent, formatLine(item, opts))
+		if len(item.Children) > 0 {
+			writeTaskTree(b, item.Children, depth+1, opts)
+		}
+	}
+}
+
+func buildTaskTree(tasks []TaskBucket, delimiter string) []TaskNode {
+	if delimiter == "" {
+		return nil
+	}
+
+	var roots []TaskNode
+	for _, task := range tasks {
+		path := splitTaskPath(task.Name, delimiter)
+		roots = insertTaskPath(roots, path, task.Total, task.Earnings)
+	}
+	sortTaskNodes(roots)
+	return roots
+}
+
+func splitTaskPath(name, delimiter string) []string {
+	parts := strings.Split(name, delimiter)
+	path := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			path = append(path, part)
+		}
+	}
+	if len(path) == 0 {
+		return []string{name}
+	}
+	return path
+}
+
+func insertTaskPath(nodes []TaskNode, path []string, total time.Duration, earnings float64) []TaskNode {
+	idx := -1
+	for i := range nodes {
+		if nodes[i].Name == path[0] {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		nodes = append(nodes, TaskNode{Name: path[0]})
+		idx = len(nodes) - 1
+	}
+	nodes[idx].Total += total
+	nodes[idx].Earnings += earnings
+	if len(path) > 1 {
+		nodes[idx].Children = insertTaskPath(nodes[idx].Children, path[1:], total, earnings)
+	}
+	return nodes
+}
+
+func sortTaskNodes(nodes []TaskNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	for i := range nodes {
+		sortTaskNodes(nodes[i].Children)
+	}
+}
+
+func billableSuffix(project ProjectBucket, opts FormatOptions) string {
+	if !opts.ShowBillable {
+		return ""
+	}
+	return fmt.Sprintf(" (billable %sh / non-billable %sh)", FormatHours(project.Billable), FormatHours(project.NonBillable))
+}
+
+func writeBillableSection(b *strings.Builder, heading string, billable, nonBillable time.Duration) {
+	fmt.Fprintf(b, "%s 請求区分\n", heading)
+	fmt.Fprintf(b, "- Billable %sh\n", FormatHours(billable))
+	fmt.Fprintf(b, "- Non-billable %sh\n", FormatHours(nonBillable))
+}
+
+func writeRangeTotal(b *strings.Builder, buckets []Bucket, opts FormatOptions) {
+	if opts.Currency == "" {
+		return
+	}
+	var total time.Duration
+	var earnings float64
+	for _, bucket := range buckets {
+		total += bucket.Total
+		earnings += bucket.Earnings
+	}
+	b.WriteString("\n")
+	if opts.Daily {
+		b.WriteString("## 合計\n")
+	} else {
+		b.WriteString("### 合計\n")
+	}
+	fmt.Fprintf(b, "- %sh %s\n", FormatHours(total), FormatMoney(earnings, opts.Currency))
+}
+
+func formatLine(item lineItem, opts FormatOptions) string {
+	line := fmt.Sprintf("%s %sh", item.Name, FormatHours(item.Total))
+	if opts.Currency != "" {
+		line += " " + FormatMoney(item.Earnings, opts.Currency)
+	}
+	return line + item.Suffix
+}
+
+type lineItem struct {
+	Name     string
+	Total    time.Duration
+	Earnings float64
+	Suffix   string
+	Children []TaskNode
+}
+
+type collapsible[T any] interface {
+	total() time.Duration
+	fold(name string, rest []T) T
+}
+
+func collapseTop[T collapsible[T]](items []T, top int) []T {
+	if top <= 0 || len(items) <= top {
+		return items
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return items[order[i]].total() > items[order[j]].total()
+	})
+	keep := make(map[int]bool, top)
+	for _, idx := range order[:top] {
+		keep[idx] = true
+	}
+
+	out := make([]T, 0, top+1)
+	var rest []T
+	for i, item := range items {
+		if keep[i] {
+			out = append(out, item)
+			continue
+		}
+		rest = append(rest, item)
+	}
+	var other T
+	return append(out, other.fold(otherLabel(len(rest)), rest))
+}
+
+func (item lineItem) total() time.Duration {
+	return item.Total
+}
+
+func (lineItem) fold(name string, rest []lineItem) lineItem {
+	other := lineItem{Name: name}
+	for _, item := range rest {
+		other.Total += item.Total
+		other.Earnings += item.Earnings
+	}
+	return other
+}
+
+func (project ProjectBucket) total() time.Duration {
+	return project.Total
+}
+
+func (ProjectBucket) fold(name string, rest []ProjectBucket) ProjectBucket {
+	other := ProjectBucket{Name: name}
+	for _, project := range rest {
+		other.Total += project.Total
+		other.Billable += project.Billable
+		other.NonBillable += project.NonBillable
+		other.Earnings += project.Earnings
+	}
+	return other
+}
+
+func otherLabel(count int) string {
+	if count == 1 {
+		return "Other (1 item)"
+	}
+	return fmt.Sprintf("Other (%d items)", count)
+}
+
 func sameDay(a, b time.Time) bool {
 	ay, am, ad := a.Date()
 	by, bm, bd := b.Date()
 	return ay == by && am == bm && ad == bd
 }
 
-func formatHours(d time.Duration) string {
+func FormatHours(d time.Duration) string {
 	hours := d.Hours()
 	rounded := math.Round(hours*100) / 100
 	return fmt.Sprintf("%.2f", rounded)
 }
 
+func FormatMoney(amount float64, currency string) string {
+	rounded := math.Round(amount*100) / 100
+	return fmt.Sprintf("%s %.2f", currency, rounded)
+}
+
+func normalizeUser(name string) string {
+	if strings.TrimSpace(name) == "" {
+		return "Unknown User"
+	}
+	return name
+}
+
+func normalizeWorkspace(name string) string {
+	if strings.TrimSpace(name) == "" {
+		return "Unknown Workspace"
+	}
+	return name
+}
+
 func normalizeProject(name string) string {
 	if strings.TrimSpace(name) == "" {
 		return "No Project"
@@ -292,3 +292,302 @@ func TestFormatMarkdownDefaultEmpty(t *testing.T) {
 		t.Fatalf("unexpected markdown:\n--- got ---\n%s\n--- want ---\n%s", got, want)
 	}
 }
+
+func TestFormatMarkdownDefaultTopCollapsesOther(t *testing.T) {
+	buckets := []Bucket{
+		{
+			Projects: []ProjectBucket{
+				{Name: "Alpha", Total: 3 * time.Hour},
+				{Name: "Beta", Total: 1 * time.Hour},
+				{Name: "Gamma", Total: 30 * time.Minute},
+			},
+			Tasks: []TaskSummary{
+				{Name: "Small", Total: 15 * time.Minute},
+				{Name: "Large", Total: 2 * time.Hour},
+				{Name: "Medium", Total: 1 * time.Hour},
+				{Name: "Tiny", Total: 15 * time.Minute},
+			},
+		},
+	}
+
+	got := FormatMarkdown(buckets, FormatOptions{
+		Format: "default",
+		Top:    2,
+	})
+	want := "" +
+		"### タスク\n" +
+		"- Large 2.00h\n" +
+		"- Medium 1.00h\n" +
+		"- Other (2 items) 0.50h\n" +
+		"\n" +
+		"### プロジェクト\n" +
+		"- Alpha 3.00h\n" +
+		"- Beta 1.00h\n" +
+		"- Other (1 item) 0.50h\n"
+
+	if got != want {
+		t.Fatalf("unexpected markdown:\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+	if len(buckets[0].Tasks) != 4 || len(buckets[0].Projects) != 3 {
+		t.Fatalf("buckets should keep the full list")
+	}
+}
+
+func TestFormatMarkdownDetailTopCollapsesTasks(t *testing.T) {
+	buckets := []Bucket{
+		{
+			Projects: []ProjectBucket{
+				{
+					Name:  "Alpha",
+					Total: 3 * time.Hour,
+					Tasks: []TaskBucket{
+						{Name: "A", Total: 30 * time.Minute},
+						{Name: "B", Total: 2 * time.Hour},
+						{Name: "C", Total: 30 * time.Minute},
+					},
+				},
+			},
+		},
+	}
+
+	got := FormatMarkdown(buckets, FormatOptions{
+		Format: "detail",
+		Top:    1,
+	})
+	want := "" +
+		"### Alpha 3.00h\n" +
+		"- B 2.00h\n" +
+		"- Other (2 items) 1.00h\n"
+
+	if got != want {
+		t.Fatalf("unexpected markdown:\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestFormatMarkdownDetailTopCollapsesProjects(t *testing.T) {
+	buckets := []Bucket{
+		{
+			Projects: []ProjectBucket{
+				{Name: "Alpha", Total: 30 * time.Minute, Tasks: []TaskBucket{{Name: "A", Total: 30 * time.Minute}}},
+				{Name: "Beta", Total: 2 * time.Hour, Tasks: []TaskBucket{{Name: "B", Total: 2 * time.Hour}}},
+				{Name: "Gamma", Total: 15 * time.Minute, Tasks: []TaskBucket{{Name: "C", Total: 15 * time.Minute}}},
+			},
+		},
+	}
+
+	got := FormatMarkdown(buckets, FormatOptions{
+		Format: "detail",
+		Top:    1,
+	})
+	want := "" +
+		"### Beta 2.00h\n" +
+		"- B 2.00h\n" +
+		"\n" +
+		"### Other (2 items) 0.75h\n"
+
+	if got != want {
+		t.Fatalf("unexpected markdown:\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestFormatMarkdownDetailTaskTree(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	entries := []Entry{
+		{
+			Project:  "Ops",
+			Task:     "Infra: k8s: upgrade",
+			Start:    time.Date(2026, 1, 10, 9, 0, 0, 0, jst),
+			Duration: 90 * time.Minute,
+		},
+		{
+			Project:  "Ops",
+			Task:     "Infra: k8s: debug",
+			Start:    time.Date(2026, 1, 10, 11, 0, 0, 0, jst),
+			Duration: 30 * time.Minute,
+		},
+		{
+			Project:  "Ops",
+			Task:     "Infra: dns",
+			Start:    time.Date(2026, 1, 10, 12, 0, 0, 0, jst),
+			Duration: 60 * time.Minute,
+		},
+		{
+			Project:  "Ops",
+			Task:     "Review",
+			Start:    time.Date(2026, 1, 10, 13, 0, 0, 0, jst),
+			Duration: 30 * time.Minute,
+		},
+	}
+
+	buckets := Aggregate(entries, AggregateOptions{
+		Location:      jst,
+		TaskDelimiter: ":",
+	})
+	got := FormatMarkdown(buckets, FormatOptions{Format: "detail"})
+	want := "" +
+		"### Ops 3.50h\n" +
+		"- Infra 3.00h\n" +
+		"  - dns 1.00h\n" +
+		"  - k8s 2.00h\n" +
+		"    - debug 0.50h\n" +
+		"    - upgrade 1.50h\n" +
+		"- Review 0.50h\n"
+
+	if got != want {
+		t.Fatalf("unexpected markdown:\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestFormatMarkdownDefaultShowBillable(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	entries := []Entry{
+		{
+			Project:  "Alpha",
+			Task:     "Design",
+			Start:    time.Date(2026, 1, 10, 9, 0, 0, 0, jst),
+			Duration: 90 * time.Minute,
+			Billable: true,
+		},
+		{
+			Project:  "Alpha",
+			Task:     "Meeting",
+			Start:    time.Date(2026, 1, 10, 11, 0, 0, 0, jst),
+			Duration: 30 * time.Minute,
+		},
+		{
+			Project:  "Beta",
+			Task:     "Admin",
+			Start:    time.Date(2026, 1, 10, 12, 0, 0, 0, jst),
+			Duration: 60 * time.Minute,
+		},
+	}
+
+	buckets := Aggregate(entries, AggregateOptions{Location: jst})
+	if buckets[0].Total != 3*time.Hour || buckets[0].Billable != 90*time.Minute || buckets[0].NonBillable != 90*time.Minute {
+		t.Fatalf("unexpected bucket totals: %+v", buckets[0])
+	}
+
+	got := FormatMarkdown(buckets, FormatOptions{
+		Format:       "default",
+		ShowBillable: true,
+	})
+	want := "" +
+		"### タスク\n" +
+		"- Design 1.50h\n" +
+		"- Meeting 0.50h\n" +
+		"- Admin 1.00h\n" +
+		"\n" +
+		"### プロジェクト\n" +
+		"- Alpha 2.00h (billable 1.50h / non-billable 0.50h)\n" +
+		"- Beta 1.00h (billable 0.00h / non-billable 1.00h)\n" +
+		"\n" +
+		"### 請求区分\n" +
+		"- Billable 1.50h\n" +
+		"- Non-billable 1.50h\n"
+
+	if got != want {
+		t.Fatalf("unexpected markdown:\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestFormatMarkdownGroupsByUser(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	entries := []Entry{
+		{
+			User:     "Bob",
+			Project:  "Alpha",
+			Task:     "Review",
+			Start:    time.Date(2026, 1, 10, 9, 0, 0, 0, jst),
+			Duration: 30 * time.Minute,
+		},
+		{
+			User:     "Alice",
+			Project:  "Alpha",
+			Task:     "Design",
+			Start:    time.Date(2026, 1, 10, 10, 0, 0, 0, jst),
+			Duration: 90 * time.Minute,
+		},
+		{
+			User:     "Alice",
+			Project:  "Beta",
+			Task:     "Build",
+			Start:    time.Date(2026, 1, 10, 11, 0, 0, 0, jst),
+			Duration: 60 * time.Minute,
+		},
+	}
+
+	buckets := Aggregate(entries, AggregateOptions{
+		Location:    jst,
+		GroupByUser: true,
+	})
+	got := FormatMarkdown(buckets, FormatOptions{Format: "default"})
+	want := "" +
+		"### Alice 2.50h\n" +
+		"- Alpha 1.50h\n" +
+		"- Beta 1.00h\n" +
+		"\n" +
+		"### Bob 0.50h\n" +
+		"- Alpha 0.50h\n"
+	if got != want {
+		t.Fatalf("unexpected markdown:\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+
+	got = FormatMarkdown(buckets, FormatOptions{Format: "detail"})
+	want = "" +
+		"### Alice 2.50h\n" +
+		"\n" +
+		"#### Alpha 1.50h\n" +
+		"- Design 1.50h\n" +
+		"\n" +
+		"#### Beta 1.00h\n" +
+		"- Build 1.00h\n" +
+		"\n" +
+		"### Bob 0.50h\n" +
+		"\n" +
+		"#### Alpha 0.50h\n" +
+		"- Review 0.50h\n"
+	if got != want {
+		t.Fatalf("unexpected markdown:\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestFormatMarkdownGroupsUseTaskTreeAndShowBillable(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	entries := []Entry{
+		{User: "Alice", Project: "Ops", Task: "Infra: dns", Start: time.Date(2026, 1, 10, 9, 0, 0, 0, jst), Duration: time.Hour, Billable: true},
+		{User: "Alice", Project: "Ops", Task: "Infra: k8s", Start: time.Date(2026, 1, 10, 10, 0, 0, 0, jst), Duration: 30 * time.Minute},
+	}
+
+	buckets := Aggregate(entries, AggregateOptions{
+		Location:      jst,
+		TaskDelimiter: ":",
+		GroupByUser:   true,
+	})
+	got := FormatMarkdown(buckets, FormatOptions{Format: "detail", ShowBillable: true})
+	want := "" +
+		"### Alice 1.50h\n" +
+		"\n" +
+		"#### Ops 1.50h (billable 1.00h / non-billable 0.50h)\n" +
+		"- Infra 1.50h\n" +
+		"  - dns 1.00h\n" +
+		"  - k8s 0.50h\n" +
+		"\n" +
+		"#### 請求区分\n" +
+		"- Billable 1.00h\n" +
+		"- Non-billable 0.50h\n"
+	if got != want {
+		t.Fatalf("unexpected markdown:\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+
+	got = FormatMarkdown(buckets, FormatOptions{Format: "default", ShowBillable: true})
+	want = "" +
+		"### Alice 1.50h\n" +
+		"- Ops 1.50h (billable 1.00h / non-billable 0.50h)\n" +
+		"\n" +
+		"#### 請求区分\n" +
+		"- Billable 1.00h\n" +
+		"- Non-billable 0.50h\n"
+	if got != want {
+		t.Fatalf("unexpected markdown:\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
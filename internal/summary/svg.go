@@ -0,0 +1,139 @@
+package summary
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+var chartPalette = []string{
+	"#06aaf5", "#c56bff", "#ea468d", "#fb8b14", "#c7af14", "#2da608",
+	"#4dc3ff", "#bc85e6", "#df7baa", "#f6b05e", "#e20505", "#525266",
+}
+
+type chartSlice struct {
+	Name  string
+	Total time.Duration
+	Color string
+}
+
+func projectShares(buckets []Bucket) []chartSlice {
+	totals := map[string]time.Duration{}
+	for _, bucket := range buckets {
+		for _, project := range bucket.Projects {
+			totals[project.Name] += project.Total
+		}
+	}
+	slices := make([]chartSlice, 0, len(totals))
+	for name, total := range totals {
+		slices = append(slices, chartSlice{Name: name, Total: total})
+	}
+	sort.Slice(slices, func(i, j int) bool {
+		if slices[i].Total == slices[j].Total {
+			return slices[i].Name < slices[j].Name
+		}
+		return slices[i].Total > slices[j].Total
+	})
+	for i := range slices {
+		slices[i].Color = chartPalette[i%len(chartPalette)]
+	}
+	return slices
+}
+
+func svgStackedBars(buckets []Bucket, shares []chartSlice) string {
+	const (
+		height   = 200.0
+		barWidth = 36.0
+		gap      = 14.0
+		left     = 48.0
+		top      = 10.0
+		bottom   = 24.0
+	)
+	colors := make(map[string]string, len(shares))
+	for _, share := range shares {
+		colors[share.Name] = share.Color
+	}
+	var max time.Duration
+	for _, bucket := range buckets {
+		if bucket.Total > max {
+			max = bucket.Total
+		}
+	}
+	if max <= 0 {
+		return ""
+	}
+
+	width := left + float64(len(buckets))*(barWidth+gap)
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" class="bars" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" role="img" aria-label="時間の推移">`, width, top+height+bottom, width, top+height+bottom)
+	fmt.Fprintf(&b, `<line x1="%.0f" y1="%.0f" x2="%.0f" y2="%.0f" stroke="#999"/>`, left-4, top+height, width, top+height)
+	fmt.Fprintf(&b, `<text x="%.0f" y="%.0f" font-size="11" text-anchor="end">%sh</text>`, left-8, top+10, FormatHours(max))
+	fmt.Fprintf(&b, `<text x="%.0f" y="%.0f" font-size="11" text-anchor="end">0h</text>`, left-8, top+height)
+	for i, bucket := range buckets {
+		x := left + float64(i)*(barWidth+gap)
+		y := top + height
+		for _, project := range bucket.Projects {
+			h := height * float64(project.Total) / float64(max)
+			y -= h
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.0f" height="%.1f" fill="%s"><title>%s %s %sh</title></rect>`,
+				x, y, barWidth, h, colors[project.Name], html.EscapeString(bucket.Date), html.EscapeString(project.Name), FormatHours(project.Total))
+		}
+		label := bucket.Date
+		if len(label) == len(dateLayout) {
+			label = label[5:]
+		}
+		if label == "" {
+			label = "Total"
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.0f" font-size="11" text-anchor="middle">%s</text>`, x+barWidth/2, top+height+16, html.EscapeString(label))
+	}
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+func svgPie(shares []chartSlice) string {
+	const (
+		size   = 200.0
+		radius = 90.0
+	)
+	var total time.Duration
+	for _, share := range shares {
+		total += share.Total
+	}
+	if total <= 0 {
+		return ""
+	}
+
+	center := size / 2
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" class="pie" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" role="img" aria-label="プロジェクト別の割合">`, size, size, size, size)
+	angle := -math.Pi / 2
+	for _, share := range shares {
+		if share.Total <= 0 {
+			continue
+		}
+		fraction := float64(share.Total) / float64(total)
+		title := fmt.Sprintf("<title>%s %sh (%.0f%%)</title>", html.EscapeString(share.Name), FormatHours(share.Total), fraction*100)
+		if fraction >= 0.9999 {
+			fmt.Fprintf(&b, `<circle cx="%.0f" cy="%.0f" r="%.0f" fill="%s">%s</circle>`, center, center, radius, share.Color, title)
+			break
+		}
+		end := angle + fraction*2*math.Pi
+		largeArc := 0
+		if fraction > 0.5 {
+			largeArc = 1
+		}
+		fmt.Fprintf(&b, `<path d="M%.0f,%.0f L%.2f,%.2f A%.0f,%.0f 0 %d 1 %.2f,%.2f Z" fill="%s">%s</path>`,
+			center, center,
+			center+radius*math.Cos(angle), center+radius*math.Sin(angle),
+			radius, radius, largeArc,
+			center+radius*math.Cos(end), center+radius*math.Sin(end),
+			share.Color, title)
+		angle = end
+	}
+	b.WriteString(`</svg>`)
+	return b.String()
+}
@@ -7,40 +7,110 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type Client struct {
-	baseURL    string
-	token      string
-	httpClient *http.Client
+	baseURL        string
+	reportsBaseURL string
+	token          string
+	httpClient     *http.Client
 }
 
 const defaultHTTPTimeout = 10 * time.Second
 
 type TimeEntry struct {
 	ID          int64
+	WorkspaceID int64
 	Description string
 	Start       time.Time
 	Duration    time.Duration
 	ProjectID   int64
 	ProjectName string
+	ClientName  string
+	UserID      int64
+	UserName    string
+	Billable    bool
+	Tags        []string
+}
+
+type SyncResult struct {
+	Entries []TimeEntry
+	Removed []int64
+}
+
+type FetchStats struct {
+	Returned     int
+	Usable       int
+	Running      int
+	ZeroDuration int
+	InvalidStart int
+	Workspaces   map[int64]int
+}
+
+type Project struct {
+	ID          int64
+	WorkspaceID int64
+	Name        string
+	ClientID    int64
+	Active      bool
+	Color       string
+}
+
+type User struct {
+	ID                 int64
+	Name               string
+	Email              string
+	DefaultWorkspaceID int64
 }
 
 type timeEntryResponse struct {
-	ID          int64   `json:"id"`
-	Description string  `json:"description"`
-	Start       string  `json:"start"`
-	Duration    int64   `json:"duration"`
-	PID         *int64  `json:"pid"`
-	ProjectID   *int64  `json:"project_id"`
-	TaskID      *int64  `json:"task_id"`
-	TID         *int64  `json:"tid"`
-	ProjectName *string `json:"project_name"`
+	ID              int64    `json:"id"`
+	WorkspaceID     *int64   `json:"workspace_id"`
+	WID             *int64   `json:"wid"`
+	Description     string   `json:"description"`
+	Start           string   `json:"start"`
+	Duration        int64    `json:"duration"`
+	PID             *int64   `json:"pid"`
+	ProjectID       *int64   `json:"project_id"`
+	TaskID          *int64   `json:"task_id"`
+	TID             *int64   `json:"tid"`
+	ProjectName     *string  `json:"project_name"`
+	Billable        bool     `json:"billable"`
+	Tags            []string `json:"tags"`
+	ServerDeletedAt *string  `json:"server_deleted_at"`
 }
 
 type projectResponse struct {
+	ID          int64  `json:"id"`
+	WorkspaceID int64  `json:"workspace_id"`
+	Name        string `json:"name"`
+	ClientID    *int64 `json:"client_id"`
+	CID         *int64 `json:"cid"`
+	Active      bool   `json:"active"`
+	Color       string `json:"color"`
+}
+
+type clientResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type tagResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type meResponse struct {
+	ID                 int64  `json:"id"`
+	Fullname           string `json:"fullname"`
+	Email              string `json:"email"`
+	DefaultWorkspaceID int64  `json:"default_workspace_id"`
+}
+
+type workspaceResponse struct {
 	ID   int64  `json:"id"`
 	Name string `json:"name"`
 }
@@ -50,133 +120,326 @@ func NewClient(baseURL, token string, httpClient *http.Client) *Client {
 		httpClient = &http.Client{Timeout: defaultHTTPTimeout}
 	}
 	return &Client{
-		baseURL:    baseURL,
-		token:      token,
-		httpClient: httpClient,
+		baseURL:        baseURL,
+		reportsBaseURL: defaultReportsBaseURL(baseURL),
+		token:          token,
+		httpClient:     httpClient,
+	}
+}
+
+func (c *Client) SetReportsBaseURL(reportsBaseURL string) {
+	if reportsBaseURL != "" {
+		c.reportsBaseURL = reportsBaseURL
 	}
 }
 
 func (c *Client) FetchTimeEntries(ctx context.Context, start, end time.Time) ([]TimeEntry, error) {
-	endpoint, err := url.JoinPath(c.baseURL, "me/time_entries")
+	raw, err := c.fetchRawTimeEntries(ctx, rangeQuery(start, end))
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	entries := make([]TimeEntry, 0, len(raw))
+	for _, item := range raw {
+		if item.Duration < 0 {
+			continue
+		}
+		entry, err := convertTimeEntry(item)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (c *Client) FetchTimeEntriesSince(ctx context.Context, since time.Time) (SyncResult, error) {
+	raw, err := c.fetchRawTimeEntries(ctx, url.Values{
+		"since": {strconv.FormatInt(since.Unix(), 10)},
+	})
 	if err != nil {
-		return nil, err
+		return SyncResult{}, err
+	}
+
+	var result SyncResult
+	for _, item := range raw {
+		if item.Duration < 0 || item.ServerDeletedAt != nil {
+			result.Removed = append(result.Removed, item.ID)
+			continue
+		}
+		entry, err := convertTimeEntry(item)
+		if err != nil {
+			return SyncResult{}, err
+		}
+		result.Entries = append(result.Entries, entry)
 	}
-	req.SetBasicAuth(c.token, "api_token")
 
-	q := req.URL.Query()
-	q.Set("start_date", start.UTC().Format(time.RFC3339))
-	q.Set("end_date", end.UTC().Format(time.RFC3339))
-	req.URL.RawQuery = q.Encode()
+	return result, nil
+}
 
-	resp, err := c.httpClient.Do(req)
+func (c *Client) FetchTimeEntryStats(ctx context.Context, start, end time.Time) (FetchStats, error) {
+	raw, err := c.fetchRawTimeEntries(ctx, rangeQuery(start, end))
+	if err != nil {
+		return FetchStats{}, err
+	}
+
+	stats := FetchStats{
+		Returned:   len(raw),
+		Workspaces: map[int64]int{},
+	}
+	for _, item := range raw {
+		switch {
+		case item.Duration < 0:
+			stats.Running++
+		case item.Duration == 0:
+			stats.ZeroDuration++
+		default:
+			entry, err := convertTimeEntry(item)
+			if err != nil {
+				stats.InvalidStart++
+				continue
+			}
+			stats.Usable++
+			stats.Workspaces[entry.WorkspaceID]++
+		}
+	}
+	return stats, nil
+}
+
+func (c *Client) fetchRawTimeEntries(ctx context.Context, query url.Values) ([]timeEntryResponse, error) {
+	endpoint, err := url.JoinPath(c.baseURL, "me/time_entries")
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	endpoint += "?" + query.Encode()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, buildAPIError(req, resp)
+	var raw []timeEntryResponse
+	if err := c.getJSON(ctx, endpoint, &raw); err != nil {
+		return nil, err
 	}
+	return raw, nil
+}
 
+func rangeQuery(start, end time.Time) url.Values {
+	return url.Values{
+		"start_date": {start.UTC().Format(time.RFC3339)},
+		"end_date":   {end.UTC().Format(time.RFC3339)},
+	}
+}
+
+func DecodeTimeEntries(r io.Reader) ([]TimeEntry, error) {
 	var raw []timeEntryResponse
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
 		return nil, err
 	}
 
 	entries := make([]TimeEntry, 0, len(raw))
 	for _, item := range raw {
-		if item.Duration < 0 {
+		if item.Duration < 0 || item.ServerDeletedAt != nil {
 			continue
 		}
-		startTime, err := time.Parse(time.RFC3339, item.Start)
+		entry, err := convertTimeEntry(item)
 		if err != nil {
-			return nil, fmt.Errorf("invalid start time: %w", err)
+			return nil, err
 		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
 
-		projectID := int64(0)
-		if item.ProjectID != nil {
-			projectID = *item.ProjectID
-		} else if item.PID != nil {
-			projectID = *item.PID
-		}
+func convertTimeEntry(item timeEntryResponse) (TimeEntry, error) {
+	startTime, err := time.Parse(time.RFC3339, item.Start)
+	if err != nil {
+		return TimeEntry{}, fmt.Errorf("invalid start time: %w", err)
+	}
 
-		projectName := ""
-		if item.ProjectName != nil {
-			projectName = *item.ProjectName
-		}
+	projectID := int64(0)
+	if item.ProjectID != nil {
+		projectID = *item.ProjectID
+	} else if item.PID != nil {
+		projectID = *item.PID
+	}
 
-		entries = append(entries, TimeEntry{
-			ID:          item.ID,
-			Description: item.Description,
-			Start:       startTime,
-			Duration:    time.Duration(item.Duration) * time.Second,
-			ProjectID:   projectID,
-			ProjectName: projectName,
-		})
+	workspaceID := int64(0)
+	if item.WorkspaceID != nil {
+		workspaceID = *item.WorkspaceID
+	} else if item.WID != nil {
+		workspaceID = *item.WID
 	}
 
-	return entries, nil
+	projectName := ""
+	if item.ProjectName != nil {
+		projectName = *item.ProjectName
+	}
+
+	return TimeEntry{
+		ID:          item.ID,
+		WorkspaceID: workspaceID,
+		Description: item.Description,
+		Start:       startTime,
+		Duration:    time.Duration(item.Duration) * time.Second,
+		ProjectID:   projectID,
+		ProjectName: projectName,
+		Billable:    item.Billable,
+		Tags:        item.Tags,
+	}, nil
 }
 
-func (c *Client) FetchProjects(ctx context.Context, workspaceID string) (map[int64]string, error) {
+func (c *Client) FetchProjects(ctx context.Context, workspaceID string) (map[int64]Project, error) {
 	endpoint, err := url.JoinPath(c.baseURL, "workspaces", workspaceID, "projects")
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
-	if err != nil {
+	var raw []projectResponse
+	if err := c.getJSON(ctx, endpoint, &raw); err != nil {
 		return nil, err
 	}
-	req.SetBasicAuth(c.token, "api_token")
 
-	resp, err := c.httpClient.Do(req)
+	projects := make(map[int64]Project, len(raw))
+	for _, item := range raw {
+		clientID := int64(0)
+		if item.ClientID != nil {
+			clientID = *item.ClientID
+		} else if item.CID != nil {
+			clientID = *item.CID
+		}
+		projects[item.ID] = Project{
+			ID:          item.ID,
+			WorkspaceID: item.WorkspaceID,
+			Name:        item.Name,
+			ClientID:    clientID,
+			Active:      item.Active,
+			Color:       item.Color,
+		}
+	}
+
+	return projects, nil
+}
+
+func (c *Client) FetchClients(ctx context.Context, workspaceID string) (map[int64]string, error) {
+	endpoint, err := url.JoinPath(c.baseURL, "workspaces", workspaceID, "clients")
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, buildAPIError(req, resp)
+	var raw []clientResponse
+	if err := c.getJSON(ctx, endpoint, &raw); err != nil {
+		return nil, err
 	}
 
-	var raw []projectResponse
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+	clients := make(map[int64]string, len(raw))
+	for _, item := range raw {
+		clients[item.ID] = item.Name
+	}
+
+	return clients, nil
+}
+
+func (c *Client) FetchMe(ctx context.Context) (User, error) {
+	endpoint, err := url.JoinPath(c.baseURL, "me")
+	if err != nil {
+		return User{}, err
+	}
+
+	var raw meResponse
+	if err := c.getJSON(ctx, endpoint, &raw); err != nil {
+		return User{}, err
+	}
+
+	return User{
+		ID:                 raw.ID,
+		Name:               raw.Fullname,
+		Email:              raw.Email,
+		DefaultWorkspaceID: raw.DefaultWorkspaceID,
+	}, nil
+}
+
+func (c *Client) FetchWorkspace(ctx context.Context, workspaceID string) (string, error) {
+	endpoint, err := url.JoinPath(c.baseURL, "workspaces", workspaceID)
+	if err != nil {
+		return "", err
+	}
+
+	var raw workspaceResponse
+	if err := c.getJSON(ctx, endpoint, &raw); err != nil {
+		return "", err
+	}
+	return raw.Name, nil
+}
+
+func (c *Client) FetchWorkspaces(ctx context.Context) (map[int64]string, error) {
+	endpoint, err := url.JoinPath(c.baseURL, "me", "workspaces")
+	if err != nil {
 		return nil, err
 	}
 
-	projects := make(map[int64]string, len(raw))
+	var raw []workspaceResponse
+	if err := c.getJSON(ctx, endpoint, &raw); err != nil {
+		return nil, err
+	}
+
+	workspaces := make(map[int64]string, len(raw))
 	for _, item := range raw {
-		projects[item.ID] = item.Name
+		workspaces[item.ID] = item.Name
 	}
 
-	return projects, nil
+	return workspaces, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, endpoint string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.token, "api_token")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return buildAPIError(req, resp)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+type APIError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	URI        string
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	if e.Body != "" {
+		return fmt.Sprintf("toggl API error: %s (%s %s): %s", e.Status, e.Method, e.URI, e.Body)
+	}
+	return fmt.Sprintf("toggl API error: %s (%s %s)", e.Status, e.Method, e.URI)
 }
 
 func buildAPIError(req *http.Request, resp *http.Response) error {
-	method := "UNKNOWN"
-	uri := ""
+	apiErr := &APIError{
+		Status: "unknown status",
+		Method: "UNKNOWN",
+	}
 	if req != nil {
-		method = req.Method
+		apiErr.Method = req.Method
 		if req.URL != nil {
-			uri = req.URL.RequestURI()
+			apiErr.URI = req.URL.RequestURI()
 		}
 	}
-	status := "unknown status"
 	if resp != nil {
-		status = resp.Status
-	}
-
-	body := readErrorBody(resp)
-	if body != "" {
-		return fmt.Errorf("toggl API error: %s (%s %s): %s", status, method, uri, body)
+		apiErr.StatusCode = resp.StatusCode
+		apiErr.Status = resp.Status
 	}
-	return fmt.Errorf("toggl API error: %s (%s %s)", status, method, uri)
+	apiErr.Body = readErrorBody(resp)
+	return apiErr
 }
 
 func readErrorBody(resp *http.Response) string {
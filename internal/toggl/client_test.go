@@ -3,6 +3,7 @@ package toggl
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -26,7 +27,7 @@ func TestClientFetchTimeEntries(t *testing.T) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte(`[
-		  {"id":1,"description":"Design","start":"2026-01-10T09:00:00Z","duration":3600,"pid":111,"project_name":"Alpha"},
+		  {"id":1,"description":"Design","start":"2026-01-10T09:00:00Z","duration":3600,"pid":111,"project_name":"Alpha","billable":true,"tags":["review","client"]},
 		  {"id":2,"description":"Running","start":"2026-01-10T10:00:00Z","duration":-1,"pid":111}
 		]`))
 	}))
@@ -50,6 +51,12 @@ func TestClientFetchTimeEntries(t *testing.T) {
 	if entries[0].ProjectName != "Alpha" {
 		t.Fatalf("unexpected project name: %s", entries[0].ProjectName)
 	}
+	if !entries[0].Billable {
+		t.Fatalf("expected billable entry")
+	}
+	if strings.Join(entries[0].Tags, ",") != "review,client" {
+		t.Fatalf("unexpected tags: %v", entries[0].Tags)
+	}
 
 	if gotQuery.Get("start_date") == "" || gotQuery.Get("end_date") == "" {
 		t.Fatalf("missing date query params: %v", gotQuery.Encode())
@@ -89,8 +96,8 @@ func TestClientFetchProjects(t *testing.T) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte(`[
-		  {"id":111,"name":"Alpha"},
-		  {"id":222,"name":"Beta"}
+		  {"id":111,"workspace_id":999,"name":"Alpha","client_id":5,"active":true,"color":"#06aaf5"},
+		  {"id":222,"workspace_id":999,"name":"Beta","cid":6}
 		]`))
 	}))
 	defer server.Close()
@@ -101,9 +108,15 @@ func TestClientFetchProjects(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if projects[111] != "Alpha" || projects[222] != "Beta" {
+	if projects[111].Name != "Alpha" || projects[222].Name != "Beta" {
 		t.Fatalf("unexpected projects: %+v", projects)
 	}
+	if projects[111].ClientID != 5 || projects[222].ClientID != 6 {
+		t.Fatalf("unexpected client ids: %+v", projects)
+	}
+	if !projects[111].Active || projects[111].Color != "#06aaf5" {
+		t.Fatalf("unexpected project metadata: %+v", projects[111])
+	}
 	if !strings.HasPrefix(gotAuth, "Basic ") {
 		t.Fatalf("missing basic auth header: %s", gotAuth)
 	}
@@ -135,3 +148,155 @@ func TestClientFetchTimeEntriesErrorIncludesCause(t *testing.T) {
 		t.Fatalf("expected request info in error, got: %s", msg)
 	}
 }
+
+func TestClientFetchClients(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v9/workspaces/999/clients" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`[{"id":5,"name":"Acme"}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/api/v9", "token-123", server.Client())
+	clients, err := client.FetchClients(context.Background(), "999")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clients[5] != "Acme" {
+		t.Fatalf("unexpected clients: %+v", clients)
+	}
+}
+
+func TestClientFetchMe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v9/me" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id":42,"fullname":"Alice","email":"alice@example.com","default_workspace_id":999}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/api/v9", "token-123", server.Client())
+	me, err := client.FetchMe(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if me.ID != 42 || me.Name != "Alice" || me.Email != "alice@example.com" || me.DefaultWorkspaceID != 999 {
+		t.Fatalf("unexpected user: %+v", me)
+	}
+}
+
+func TestClientFetchWorkspaces(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v9/me/workspaces" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`[{"id":999,"name":"Personal"},{"id":888,"name":"Company"}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/api/v9", "token-123", server.Client())
+	workspaces, err := client.FetchWorkspaces(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if workspaces[999] != "Personal" || workspaces[888] != "Company" {
+		t.Fatalf("unexpected workspaces: %+v", workspaces)
+	}
+}
+
+func TestClientFetchTimeEntriesSince(t *testing.T) {
+	var gotQuery url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`[
+		  {"id":1,"description":"Design","start":"2026-01-10T09:00:00Z","duration":3600},
+		  {"id":2,"description":"Gone","start":"2026-01-10T10:00:00Z","duration":600,"server_deleted_at":"2026-01-10T11:00:00Z"},
+		  {"id":3,"description":"Running","start":"2026-01-10T12:00:00Z","duration":-1}
+		]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/api/v9", "token-123", server.Client())
+	since := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	result, err := client.FetchTimeEntriesSince(context.Background(), since)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotQuery.Get("since") != "1768003200" {
+		t.Fatalf("unexpected since param: %s", gotQuery.Get("since"))
+	}
+	if len(result.Entries) != 1 || result.Entries[0].ID != 1 {
+		t.Fatalf("unexpected entries: %+v", result.Entries)
+	}
+	if len(result.Removed) != 2 || result.Removed[0] != 2 || result.Removed[1] != 3 {
+		t.Fatalf("unexpected removed ids: %v", result.Removed)
+	}
+}
+
+func TestClientFetchTimeEntryStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+		  {"id":1,"description":"Design","start":"2026-01-10T09:00:00Z","duration":3600,"wid":999},
+		  {"id":2,"description":"Other","start":"2026-01-10T11:00:00Z","duration":600,"wid":888},
+		  {"id":3,"description":"Running","start":"2026-01-10T10:00:00Z","duration":-1,"wid":999},
+		  {"id":4,"description":"Blip","start":"2026-01-10T12:00:00Z","duration":0,"wid":999},
+		  {"id":5,"description":"Broken","start":"yesterday","duration":60,"wid":999}
+		]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/api/v9", "token-123", server.Client())
+	start := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	stats, err := client.FetchTimeEntryStats(context.Background(), start, start.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stats.Returned != 5 || stats.Usable != 2 || stats.Running != 1 || stats.ZeroDuration != 1 || stats.InvalidStart != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if stats.Workspaces[999] != 1 || stats.Workspaces[888] != 1 {
+		t.Fatalf("unexpected workspace counts: %v", stats.Workspaces)
+	}
+}
+
+func TestClientFetchWorkspaceReturnsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v9/workspaces/999" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":999,"name":"Personal"}`))
+			return
+		}
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/api/v9", "token-123", server.Client())
+	name, err := client.FetchWorkspace(context.Background(), "999")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "Personal" {
+		t.Fatalf("unexpected name: %s", name)
+	}
+
+	_, err = client.FetchWorkspace(context.Background(), "888")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 APIError, got %v", err)
+	}
+}
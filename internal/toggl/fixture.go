@@ -0,0 +1,139 @@
+package toggl
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type fixture struct {
+	Method      string      `json:"method"`
+	Path        string      `json:"path"`
+	Query       string      `json:"query,omitempty"`
+	Status      int         `json:"status"`
+	ContentType string      `json:"content_type,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        string      `json:"body"`
+}
+
+var unsafeFixtureChars = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+func NewRecordingHTTPClient(dir string, base http.RoundTripper) *http.Client {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &http.Client{
+		Timeout:   defaultHTTPTimeout,
+		Transport: &recordingTransport{dir: dir, base: base},
+	}
+}
+
+func NewReplayHTTPClient(dir string) *http.Client {
+	return &http.Client{
+		Timeout:   defaultHTTPTimeout,
+		Transport: &replayTransport{dir: dir},
+	}
+}
+
+type recordingTransport struct {
+	dir  string
+	base http.RoundTripper
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	item := fixture{
+		Method:      req.Method,
+		Path:        req.URL.Path,
+		Query:       req.URL.Query().Encode(),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Header:      resp.Header.Clone(),
+		Body:        string(body),
+	}
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(t.dir, fixtureName(req)), append(data, '\n'), 0o644); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type replayTransport struct {
+	dir string
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := filepath.Join(t.dir, fixtureName(req))
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no fixture for request %s %s in %s; replay with the same --date/--from/--to and timezone as the recording", req.Method, req.URL.RequestURI(), t.dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var item fixture
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+	return fixtureResponse(req, item), nil
+}
+
+func fixtureResponse(req *http.Request, item fixture) *http.Response {
+	header := item.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	if item.ContentType != "" && header.Get("Content-Type") == "" {
+		header.Set("Content-Type", item.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", item.Status, http.StatusText(item.Status)),
+		StatusCode:    item.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(item.Body)),
+		ContentLength: int64(len(item.Body)),
+		Request:       req,
+	}
+}
+
+func fixtureName(req *http.Request) string {
+	key := req.Method + " " + req.URL.Path + "?" + req.URL.Query().Encode()
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			key += "\n" + string(data)
+		}
+	}
+	sum := sha256.Sum256([]byte(key))
+	label := strings.Trim(unsafeFixtureChars.ReplaceAllString(req.URL.Path, "_"), "_")
+	return fmt.Sprintf("%s_%s_%s.json", strings.ToLower(req.Method), label, hex.EncodeToString(sum[:])[:12])
+}
@@ -0,0 +1,139 @@
+package toggl_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/toggl"
+	"github.com/yone/toggl-daily-summary/internal/toggl/toggltest"
+)
+
+func TestRecordThenReplay(t *testing.T) {
+	server := toggltest.NewServer()
+	server.AddTimeEntries(toggl.TimeEntry{
+		ID:          1,
+		Description: "Design",
+		Start:       time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC),
+		Duration:    time.Hour,
+		ProjectID:   111,
+	})
+	server.AddProjects("999", toggl.Project{ID: 111, WorkspaceID: 999, Name: "Alpha"})
+
+	dir := t.TempDir()
+	start := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)
+
+	recorder := toggl.NewClient(server.URL, "secret-token", toggl.NewRecordingHTTPClient(dir, server.Client().Transport))
+	if _, err := recorder.FetchTimeEntries(context.Background(), start, end); err != nil {
+		t.Fatalf("unexpected record error: %v", err)
+	}
+	if _, err := recorder.FetchProjects(context.Background(), "999"); err != nil {
+		t.Fatalf("unexpected record error: %v", err)
+	}
+	baseURL := server.URL
+	server.Close()
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 fixtures, got %d", len(files))
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(dir + "/" + file.Name())
+		if err != nil {
+			t.Fatalf("unexpected read error: %v", err)
+		}
+		if strings.Contains(string(data), "secret-token") {
+			t.Fatalf("fixture leaks token: %s", file.Name())
+		}
+	}
+
+	replayer := toggl.NewClient(baseURL, "", toggl.NewReplayHTTPClient(dir))
+	entries, err := replayer.FetchTimeEntries(context.Background(), start, end)
+	if err != nil {
+		t.Fatalf("unexpected replay error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Description != "Design" {
+		t.Fatalf("unexpected replayed entries: %+v", entries)
+	}
+	projects, err := replayer.FetchProjects(context.Background(), "999")
+	if err != nil {
+		t.Fatalf("unexpected replay error: %v", err)
+	}
+	if projects[111].Name != "Alpha" {
+		t.Fatalf("unexpected replayed projects: %+v", projects)
+	}
+
+	if _, err := replayer.FetchClients(context.Background(), "999"); err == nil {
+		t.Fatalf("expected error for unrecorded request")
+	}
+}
+
+func TestRecordThenReplayKeepsReportPages(t *testing.T) {
+	server := toggltest.NewServer()
+	for i := 0; i < 60; i++ {
+		server.AddTimeEntries(toggl.TimeEntry{
+			ID:          int64(i + 1),
+			Description: "Work",
+			Start:       time.Date(2026, 1, 10, 9, 0, i, 0, time.UTC),
+			Duration:    time.Minute,
+			UserID:      1,
+			UserName:    "Alice",
+		})
+	}
+
+	dir := t.TempDir()
+	start := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)
+	recorder := toggl.NewClient(server.URL, "token", toggl.NewRecordingHTTPClient(dir, server.Client().Transport))
+	if _, err := recorder.FetchWorkspaceTimeEntries(context.Background(), "999", start, end, nil); err != nil {
+		t.Fatalf("unexpected record error: %v", err)
+	}
+	baseURL := server.URL
+	server.Close()
+
+	replayer := toggl.NewClient(baseURL, "", toggl.NewReplayHTTPClient(dir))
+	entries, err := replayer.FetchWorkspaceTimeEntries(context.Background(), "999", start, end, nil)
+	if err != nil {
+		t.Fatalf("unexpected replay error: %v", err)
+	}
+	if len(entries) != 60 {
+		t.Fatalf("expected 60 replayed entries across pages, got %d", len(entries))
+	}
+}
+
+func TestReplayRejectsRequestsThatWereNotRecorded(t *testing.T) {
+	server := toggltest.NewServer()
+	server.AddTimeEntries(toggl.TimeEntry{
+		ID:          1,
+		Description: "Design",
+		Start:       time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC),
+		Duration:    time.Hour,
+	})
+
+	dir := t.TempDir()
+	jst := time.FixedZone("JST", 9*60*60)
+	recorder := toggl.NewClient(server.URL, "token", toggl.NewRecordingHTTPClient(dir, server.Client().Transport))
+	if _, err := recorder.FetchTimeEntries(context.Background(), time.Date(2026, 1, 10, 0, 0, 0, 0, jst), time.Date(2026, 1, 11, 0, 0, 0, 0, jst)); err != nil {
+		t.Fatalf("unexpected record error: %v", err)
+	}
+	baseURL := server.URL
+	server.Close()
+
+	replayer := toggl.NewClient(baseURL, "", toggl.NewReplayHTTPClient(dir))
+	pst := time.FixedZone("PST", -8*60*60)
+	for _, start := range []time.Time{
+		time.Date(2026, 1, 10, 0, 0, 0, 0, pst),
+		time.Date(2026, 3, 10, 0, 0, 0, 0, jst),
+	} {
+		_, err := replayer.FetchTimeEntries(context.Background(), start, start.AddDate(0, 0, 1))
+		if err == nil || !strings.Contains(err.Error(), "no fixture for request GET") || !strings.Contains(err.Error(), "--date/--from/--to and timezone") {
+			t.Fatalf("expected missing fixture error for %s, got %v", start, err)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package toggl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const reportsPageSize = 50
+
+type reportRequest struct {
+	StartDate      string  `json:"start_date"`
+	EndDate        string  `json:"end_date"`
+	UserIDs        []int64 `json:"user_ids,omitempty"`
+	PageSize       int     `json:"page_size"`
+	FirstRowNumber int     `json:"first_row_number,omitempty"`
+}
+
+type reportRow struct {
+	UserID      int64   `json:"user_id"`
+	Username    string  `json:"username"`
+	ProjectID   *int64  `json:"project_id"`
+	Description string  `json:"description"`
+	Billable    bool    `json:"billable"`
+	TagIDs      []int64 `json:"tag_ids"`
+	TimeEntries []struct {
+		ID      int64  `json:"id"`
+		Seconds int64  `json:"seconds"`
+		Start   string `json:"start"`
+	} `json:"time_entries"`
+}
+
+func defaultReportsBaseURL(baseURL string) string {
+	trimmed := strings.TrimRight(baseURL, "/")
+	if strings.HasSuffix(trimmed, "/api/v9") {
+		return strings.TrimSuffix(trimmed, "/api/v9") + "/reports/api/v3"
+	}
+	return "https://api.track.toggl.com/reports/api/v3"
+}
+
+func (c *Client) FetchWorkspaceTimeEntries(ctx context.Context, workspaceID string, start, end time.Time, userIDs []int64) ([]TimeEntry, error) {
+	endpoint, err := url.JoinPath(c.reportsBaseURL, "workspace", workspaceID, "search/time_entries")
+	if err != nil {
+		return nil, err
+	}
+	wid, _ := strconv.ParseInt(workspaceID, 10, 64)
+
+	body := reportRequest{
+		StartDate: start.Format("2006-01-02"),
+		EndDate:   end.Format("2006-01-02"),
+		UserIDs:   userIDs,
+		PageSize:  reportsPageSize,
+	}
+
+	var entries []TimeEntry
+	var tags map[int64]string
+	for {
+		rows, next, err := c.postReport(ctx, endpoint, body)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			projectID := int64(0)
+			if row.ProjectID != nil {
+				projectID = *row.ProjectID
+			}
+			if len(row.TagIDs) > 0 && tags == nil {
+				if tags, err = c.fetchTags(ctx, workspaceID); err != nil {
+					return nil, err
+				}
+			}
+			rowTags := tagNames(row.TagIDs, tags)
+			for _, item := range row.TimeEntries {
+				if item.Seconds < 0 {
+					continue
+				}
+				startTime, err := time.Parse(time.RFC3339, item.Start)
+				if err != nil {
+					return nil, fmt.Errorf("invalid start time: %w", err)
+				}
+				if startTime.Before(start) || !startTime.Before(end) {
+					continue
+				}
+				entries = append(entries, TimeEntry{
+					ID:          item.ID,
+					WorkspaceID: wid,
+					Description: row.Description,
+					Start:       startTime,
+					Duration:    time.Duration(item.Seconds) * time.Second,
+					ProjectID:   projectID,
+					UserID:      row.UserID,
+					UserName:    row.Username,
+					Billable:    row.Billable,
+					Tags:        rowTags,
+				})
+			}
+		}
+		if next == 0 {
+			break
+		}
+		body.FirstRowNumber = next
+	}
+
+	return entries, nil
+}
+
+func (c *Client) fetchTags(ctx context.Context, workspaceID string) (map[int64]string, error) {
+	endpoint, err := url.JoinPath(c.baseURL, "workspaces", workspaceID, "tags")
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []tagResponse
+	if err := c.getJSON(ctx, endpoint, &raw); err != nil {
+		return nil, err
+	}
+
+	tags := make(map[int64]string, len(raw))
+	for _, item := range raw {
+		tags[item.ID] = item.Name
+	}
+	return tags, nil
+}
+
+func tagNames(ids []int64, tags map[int64]string) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name, ok := tags[id]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, strconv.FormatInt(id, 10))
+		}
+	}
+	return names
+}
+
+func (c *Client) postReport(ctx context.Context, endpoint string, body reportRequest) ([]reportRow, int, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.SetBasicAuth(c.token, "api_token")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, 0, buildAPIError(req, resp)
+	}
+
+	var rows []reportRow
+	if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+		return nil, 0, err
+	}
+
+	next := 0
+	if value := resp.Header.Get("X-Next-Row-Number"); value != "" {
+		next, err = strconv.Atoi(value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid X-Next-Row-Number: %s", value)
+		}
+	}
+	return rows, next, nil
+}
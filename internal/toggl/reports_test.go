@@ -0,0 +1,96 @@
+package toggl_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/toggl"
+	"github.com/yone/toggl-daily-summary/internal/toggl/toggltest"
+)
+
+func TestClientFetchWorkspaceTimeEntriesPaginates(t *testing.T) {
+	server := toggltest.NewServer()
+	defer server.Close()
+	for i := 0; i < 60; i++ {
+		user := int64(1)
+		name := "Alice"
+		if i%2 == 1 {
+			user, name = 2, "Bob"
+		}
+		server.AddTimeEntries(toggl.TimeEntry{
+			ID:          int64(i + 1),
+			Description: "Work",
+			Start:       time.Date(2026, 1, 10, 9, 0, i, 0, time.UTC),
+			Duration:    time.Minute,
+			ProjectID:   111,
+			UserID:      user,
+			UserName:    name,
+		})
+	}
+
+	client := toggl.NewClient(server.URL, "token", server.Client())
+	start := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)
+	entries, err := client.FetchWorkspaceTimeEntries(context.Background(), "999", start, end, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 60 {
+		t.Fatalf("expected 60 entries, got %d", len(entries))
+	}
+	if entries[1].UserName != "Bob" || entries[1].UserID != 2 || entries[1].ProjectID != 111 || entries[1].WorkspaceID != 999 {
+		t.Fatalf("unexpected entry: %+v", entries[1])
+	}
+
+	reportRequests := 0
+	for _, req := range server.Requests() {
+		if strings.HasPrefix(req, "POST /reports/api/v3/workspace/999/search/time_entries") {
+			reportRequests++
+		}
+	}
+	if reportRequests != 2 {
+		t.Fatalf("expected 2 paged requests, got %d", reportRequests)
+	}
+
+	filtered, err := client.FetchWorkspaceTimeEntries(context.Background(), "999", start, end, []int64{1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(filtered) != 30 {
+		t.Fatalf("expected 30 entries for user 1, got %d", len(filtered))
+	}
+}
+
+func TestClientFetchWorkspaceTimeEntriesMapsTags(t *testing.T) {
+	server := toggltest.NewServer()
+	defer server.Close()
+	server.AddTag("999", 7, "client")
+	server.AddTag("999", 8, "review")
+	server.AddTimeEntries(
+		toggl.TimeEntry{ID: 1, Description: "Design", Start: time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC), Duration: time.Hour, UserID: 1, UserName: "Alice", Tags: []string{"review", "client"}},
+		toggl.TimeEntry{ID: 2, Description: "Build", Start: time.Date(2026, 1, 10, 11, 0, 0, 0, time.UTC), Duration: time.Hour, UserID: 1, UserName: "Alice"},
+	)
+
+	client := toggl.NewClient(server.URL, "token", server.Client())
+	start := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)
+	entries, err := client.FetchWorkspaceTimeEntries(context.Background(), "999", start, end, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 || strings.Join(entries[0].Tags, ",") != "review,client" || len(entries[1].Tags) != 0 {
+		t.Fatalf("unexpected tags: %+v", entries)
+	}
+
+	tagRequests := 0
+	for _, req := range server.Requests() {
+		if req == "GET /api/v9/workspaces/999/tags" {
+			tagRequests++
+		}
+	}
+	if tagRequests != 1 {
+		t.Fatalf("expected tags to be fetched once, got %d", tagRequests)
+	}
+}
@@ -0,0 +1,357 @@
+package toggltest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/toggl"
+)
+
+const (
+	apiPrefix     = "/api/v9"
+	reportsPrefix = "/reports/api/v3"
+)
+
+type Server struct {
+	URL string
+
+	server      *httptest.Server
+	mu          sync.Mutex
+	timeEntries []toggl.TimeEntry
+	projects    map[string][]toggl.Project
+	clients     map[string]map[int64]string
+	tags        map[string]map[int64]string
+	workspaces  map[int64]string
+	me          toggl.User
+	token       string
+	requests    []string
+}
+
+func NewServer() *Server {
+	s := &Server{
+		projects:   map[string][]toggl.Project{},
+		clients:    map[string]map[int64]string{},
+		tags:       map[string]map[int64]string{},
+		workspaces: map[int64]string{},
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	s.URL = s.server.URL + apiPrefix
+	return s
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+func (s *Server) Client() *http.Client {
+	return s.server.Client()
+}
+
+func (s *Server) AddTimeEntries(entries ...toggl.TimeEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timeEntries = append(s.timeEntries, entries...)
+}
+
+func (s *Server) AddProjects(workspaceID string, projects ...toggl.Project) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.projects[workspaceID] = append(s.projects[workspaceID], projects...)
+}
+
+func (s *Server) AddClient(workspaceID string, id int64, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.clients[workspaceID] == nil {
+		s.clients[workspaceID] = map[int64]string{}
+	}
+	s.clients[workspaceID][id] = name
+}
+
+func (s *Server) AddTag(workspaceID string, id int64, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tags[workspaceID] == nil {
+		s.tags[workspaceID] = map[int64]string{}
+	}
+	s.tags[workspaceID][id] = name
+}
+
+func (s *Server) AddWorkspace(id int64, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.workspaces[id] = name
+}
+
+func (s *Server) SetMe(user toggl.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.me = user
+}
+
+func (s *Server) RequireToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+
+	user, _, ok := r.BasicAuth()
+	if !ok || (s.token != "" && user != s.token) {
+		http.Error(w, "Incorrect username and/or password", http.StatusForbidden)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, reportsPrefix) {
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, reportsPrefix), "/"), "/")
+		if r.Method == http.MethodPost && len(parts) == 4 && parts[0] == "workspace" && parts[2] == "search" && parts[3] == "time_entries" {
+			s.writeReport(w, r, parts[1])
+			return
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, apiPrefix)
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case path == "/me/time_entries":
+		s.writeTimeEntries(w, r)
+	case path == "/me":
+		writeJSON(w, map[string]any{
+			"id":                   s.me.ID,
+			"fullname":             s.me.Name,
+			"email":                s.me.Email,
+			"default_workspace_id": s.me.DefaultWorkspaceID,
+		})
+	case path == "/me/workspaces":
+		s.writeWorkspaces(w)
+	case len(parts) == 2 && parts[0] == "workspaces":
+		s.writeWorkspace(w, r, parts[1])
+	case len(parts) == 3 && parts[0] == "workspaces" && parts[2] == "projects":
+		s.writeProjects(w, parts[1])
+	case len(parts) == 3 && parts[0] == "workspaces" && parts[2] == "clients":
+		s.writeClients(w, parts[1])
+	case len(parts) == 3 && parts[0] == "workspaces" && parts[2] == "tags":
+		s.writeTags(w, parts[1])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) writeTimeEntries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var start, end time.Time
+	var err error
+	if v := q.Get("start_date"); v != "" {
+		if start, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, "invalid start_date", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("end_date"); v != "" {
+		if end, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, "invalid end_date", http.StatusBadRequest)
+			return
+		}
+	}
+
+	out := make([]map[string]any, 0, len(s.timeEntries))
+	for _, entry := range s.timeEntries {
+		if !start.IsZero() && entry.Start.Before(start) {
+			continue
+		}
+		if !end.IsZero() && !entry.Start.Before(end) {
+			continue
+		}
+		item := map[string]any{
+			"id":           entry.ID,
+			"workspace_id": entry.WorkspaceID,
+			"description":  entry.Description,
+			"start":        entry.Start.UTC().Format(time.RFC3339),
+			"duration":     int64(entry.Duration / time.Second),
+			"billable":     entry.Billable,
+			"tags":         entry.Tags,
+		}
+		if entry.ProjectID != 0 {
+			item["project_id"] = entry.ProjectID
+		}
+		if entry.ProjectName != "" {
+			item["project_name"] = entry.ProjectName
+		}
+		out = append(out, item)
+	}
+	writeJSON(w, out)
+}
+
+func (s *Server) writeProjects(w http.ResponseWriter, workspaceID string) {
+	out := make([]map[string]any, 0, len(s.projects[workspaceID]))
+	for _, project := range s.projects[workspaceID] {
+		item := map[string]any{
+			"id":           project.ID,
+			"workspace_id": project.WorkspaceID,
+			"name":         project.Name,
+			"active":       project.Active,
+			"color":        project.Color,
+		}
+		if project.ClientID != 0 {
+			item["client_id"] = project.ClientID
+		}
+		out = append(out, item)
+	}
+	writeJSON(w, out)
+}
+
+func (s *Server) writeClients(w http.ResponseWriter, workspaceID string) {
+	out := make([]map[string]any, 0, len(s.clients[workspaceID]))
+	for id, name := range s.clients[workspaceID] {
+		out = append(out, map[string]any{
+			"id":   id,
+			"wid":  parseID(workspaceID),
+			"name": name,
+		})
+	}
+	writeJSON(w, out)
+}
+
+func (s *Server) writeTags(w http.ResponseWriter, workspaceID string) {
+	out := make([]map[string]any, 0, len(s.tags[workspaceID]))
+	for id, name := range s.tags[workspaceID] {
+		out = append(out, map[string]any{
+			"id":           id,
+			"workspace_id": parseID(workspaceID),
+			"name":         name,
+		})
+	}
+	writeJSON(w, out)
+}
+
+func (s *Server) tagIDs(workspaceID string, names []string) []int64 {
+	var ids []int64
+	for _, name := range names {
+		for id, tag := range s.tags[workspaceID] {
+			if tag == name {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+func (s *Server) writeWorkspaces(w http.ResponseWriter) {
+	out := make([]map[string]any, 0, len(s.workspaces))
+	for id, name := range s.workspaces {
+		out = append(out, map[string]any{
+			"id":   id,
+			"name": name,
+		})
+	}
+	writeJSON(w, out)
+}
+
+func (s *Server) writeWorkspace(w http.ResponseWriter, r *http.Request, workspaceID string) {
+	id, err := strconv.ParseInt(workspaceID, 10, 64)
+	name, ok := s.workspaces[id]
+	if err != nil || !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, map[string]any{
+		"id":   id,
+		"name": name,
+	})
+}
+
+func (s *Server) writeReport(w http.ResponseWriter, r *http.Request, workspaceID string) {
+	var body struct {
+		StartDate      string  `json:"start_date"`
+		EndDate        string  `json:"end_date"`
+		UserIDs        []int64 `json:"user_ids"`
+		PageSize       int     `json:"page_size"`
+		FirstRowNumber int     `json:"first_row_number"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	users := map[int64]bool{}
+	for _, id := range body.UserIDs {
+		users[id] = true
+	}
+
+	rows := make([]map[string]any, 0, len(s.timeEntries))
+	for _, entry := range s.timeEntries {
+		if len(users) > 0 && !users[entry.UserID] {
+			continue
+		}
+		date := entry.Start.Format("2006-01-02")
+		if (body.StartDate != "" && date < body.StartDate) || (body.EndDate != "" && date > body.EndDate) {
+			continue
+		}
+		row := map[string]any{
+			"user_id":     entry.UserID,
+			"username":    entry.UserName,
+			"description": entry.Description,
+			"billable":    entry.Billable,
+			"time_entries": []map[string]any{{
+				"id":      entry.ID,
+				"seconds": int64(entry.Duration / time.Second),
+				"start":   entry.Start.Format(time.RFC3339),
+			}},
+		}
+		if entry.ProjectID != 0 {
+			row["project_id"] = entry.ProjectID
+		}
+		if tagIDs := s.tagIDs(workspaceID, entry.Tags); len(tagIDs) > 0 {
+			row["tag_ids"] = tagIDs
+		}
+		rows = append(rows, row)
+	}
+
+	first := body.FirstRowNumber
+	if first < 1 {
+		first = 1
+	}
+	size := body.PageSize
+	if size <= 0 {
+		size = 50
+	}
+	startIdx := first - 1
+	if startIdx > len(rows) {
+		startIdx = len(rows)
+	}
+	endIdx := startIdx + size
+	if endIdx < len(rows) {
+		w.Header().Set("X-Next-Row-Number", strconv.Itoa(endIdx+1))
+	} else {
+		endIdx = len(rows)
+	}
+	writeJSON(w, rows[startIdx:endIdx])
+}
+
+func parseID(value string) int64 {
+	id, _ := strconv.ParseInt(value, 10, 64)
+	return id
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,314 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/summary"
+)
+
+const (
+	defaultHTTPTimeout     = 10 * time.Second
+	defaultSignatureHeader = "X-Signature-256"
+	defaultBackoff         = time.Second
+	maxBackoff             = time.Minute
+	MaxRetries             = 10
+)
+
+type Payload struct {
+	Range    Range    `json:"range"`
+	Total    Total    `json:"total"`
+	Buckets  []Bucket `json:"buckets"`
+	Markdown string   `json:"markdown"`
+}
+
+type Range struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone"`
+}
+
+type Total struct {
+	Seconds            int64   `json:"seconds"`
+	Hours              float64 `json:"hours"`
+	BillableSeconds    int64   `json:"billable_seconds"`
+	NonBillableSeconds int64   `json:"non_billable_seconds"`
+	Earnings           float64 `json:"earnings,omitempty"`
+	Currency           string  `json:"currency,omitempty"`
+}
+
+type Bucket struct {
+	Date               string    `json:"date,omitempty"`
+	Seconds            int64     `json:"seconds"`
+	Hours              float64   `json:"hours"`
+	BillableSeconds    int64     `json:"billable_seconds"`
+	NonBillableSeconds int64     `json:"non_billable_seconds"`
+	Earnings           float64   `json:"earnings,omitempty"`
+	Projects           []Project `json:"projects"`
+	Tasks              []Item    `json:"tasks"`
+	Users              []Group   `json:"users,omitempty"`
+	Workspaces         []Group   `json:"workspaces,omitempty"`
+}
+
+type Project struct {
+	Name               string  `json:"name"`
+	Seconds            int64   `json:"seconds"`
+	Hours              float64 `json:"hours"`
+	BillableSeconds    int64   `json:"billable_seconds"`
+	NonBillableSeconds int64   `json:"non_billable_seconds"`
+	Earnings           float64 `json:"earnings,omitempty"`
+	Tasks              []Item  `json:"tasks"`
+}
+
+type Group struct {
+	Name     string    `json:"name"`
+	Seconds  int64     `json:"seconds"`
+	Hours    float64   `json:"hours"`
+	Earnings float64   `json:"earnings,omitempty"`
+	Projects []Project `json:"projects"`
+}
+
+type Item struct {
+	Name     string  `json:"name"`
+	Seconds  int64   `json:"seconds"`
+	Hours    float64 `json:"hours"`
+	Earnings float64 `json:"earnings,omitempty"`
+}
+
+type PayloadOptions struct {
+	RangeStart time.Time
+	RangeEnd   time.Time
+	Location   *time.Location
+	Currency   string
+	Markdown   string
+}
+
+type Options struct {
+	URL             string
+	Headers         map[string]string
+	Secret          string
+	SignatureHeader string
+	ContentType     string
+	Retries         int
+	Backoff         time.Duration
+}
+
+type Client struct {
+	opts       Options
+	httpClient *http.Client
+}
+
+func NewPayload(buckets []summary.Bucket, opts PayloadOptions) Payload {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	payload := Payload{
+		Range: Range{
+			Start:    opts.RangeStart.In(loc).Format("2006-01-02"),
+			End:      opts.RangeEnd.In(loc).AddDate(0, 0, -1).Format("2006-01-02"),
+			Timezone: loc.String(),
+		},
+		Total:    Total{Currency: opts.Currency},
+		Buckets:  make([]Bucket, 0, len(buckets)),
+		Markdown: opts.Markdown,
+	}
+
+	var total, billable, nonBillable time.Duration
+	for _, bucket := range buckets {
+		total += bucket.Total
+		billable += bucket.Billable
+		nonBillable += bucket.NonBillable
+		payload.Total.Earnings += bucket.Earnings
+
+		out := Bucket{
+			Date:               bucket.Date,
+			Seconds:            seconds(bucket.Total),
+			Hours:              hours(bucket.Total),
+			BillableSeconds:    seconds(bucket.Billable),
+			NonBillableSeconds: seconds(bucket.NonBillable),
+			Earnings:           bucket.Earnings,
+			Projects:           convertProjects(bucket.Projects),
+			Tasks:              make([]Item, 0, len(bucket.Tasks)),
+			Users:              convertGroups(bucket.Users),
+			Workspaces:         convertGroups(bucket.Workspaces),
+		}
+		for _, task := range bucket.Tasks {
+			out.Tasks = append(out.Tasks, Item{Name: task.Name, Seconds: seconds(task.Total), Hours: hours(task.Total), Earnings: task.Earnings})
+		}
+		payload.Buckets = append(payload.Buckets, out)
+	}
+	payload.Total.Seconds = seconds(total)
+	payload.Total.Hours = hours(total)
+	payload.Total.BillableSeconds = seconds(billable)
+	payload.Total.NonBillableSeconds = seconds(nonBillable)
+	return payload
+}
+
+func convertProjects(projects []summary.ProjectBucket) []Project {
+	out := make([]Project, 0, len(projects))
+	for _, project := range projects {
+		item := Project{
+			Name:               project.Name,
+			Seconds:            seconds(project.Total),
+			Hours:              hours(project.Total),
+			BillableSeconds:    seconds(project.Billable),
+			NonBillableSeconds: seconds(project.NonBillable),
+			Earnings:           project.Earnings,
+			Tasks:              make([]Item, 0, len(project.Tasks)),
+		}
+		for _, task := range project.Tasks {
+			item.Tasks = append(item.Tasks, Item{Name: task.Name, Seconds: seconds(task.Total), Hours: hours(task.Total), Earnings: task.Earnings})
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+func convertGroups(groups []summary.GroupBucket) []Group {
+	if len(groups) == 0 {
+		return nil
+	}
+	out := make([]Group, 0, len(groups))
+	for _, group := range groups {
+		out = append(out, Group{
+			Name:     group.Name,
+			Seconds:  seconds(group.Total),
+			Hours:    hours(group.Total),
+			Earnings: group.Earnings,
+			Projects: convertProjects(group.Projects),
+		})
+	}
+	return out
+}
+
+func seconds(d time.Duration) int64 {
+	return int64(d / time.Second)
+}
+
+func hours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
+
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("body").Funcs(template.FuncMap{
+		"json": func(value any) (string, error) {
+			data, err := json.Marshal(value)
+			return string(data), err
+		},
+	}).Parse(text)
+}
+
+func Render(bodyTemplate string, payload Payload) ([]byte, error) {
+	if bodyTemplate == "" {
+		return json.Marshal(payload)
+	}
+	tmpl, err := ParseTemplate(bodyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook body template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, payload); err != nil {
+		return nil, fmt.Errorf("render webhook body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func NewClient(opts Options, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	if opts.SignatureHeader == "" {
+		opts.SignatureHeader = defaultSignatureHeader
+	}
+	if opts.Backoff == 0 {
+		opts.Backoff = defaultBackoff
+	}
+	if opts.Backoff > maxBackoff {
+		opts.Backoff = maxBackoff
+	}
+	opts.Retries = min(max(opts.Retries, 0), MaxRetries)
+	return &Client{
+		opts:       opts,
+		httpClient: httpClient,
+	}
+}
+
+func (c *Client) Send(ctx context.Context, body []byte) error {
+	var lastErr error
+	for attempt := 0; attempt <= c.opts.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.backoff(attempt)):
+			}
+		}
+		retry, err := c.send(ctx, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return lastErr
+}
+
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.opts.Backoff
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
+
+func (c *Client) send(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	if c.opts.ContentType != "" {
+		req.Header.Set("Content-Type", c.opts.ContentType)
+	}
+	for key, value := range c.opts.Headers {
+		req.Header.Set(key, value)
+	}
+	if c.opts.Secret != "" {
+		req.Header.Set(c.opts.SignatureHeader, Sign(c.opts.Secret, body))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+	if text := strings.TrimSpace(string(data)); text != "" {
+		return retry, fmt.Errorf("webhook error: %s: %s", resp.Status, text)
+	}
+	return retry, fmt.Errorf("webhook error: %s", resp.Status)
+}
@@ -0,0 +1,196 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yone/toggl-daily-summary/internal/summary"
+)
+
+func TestNewPayloadIncludesRangeAndTotals(t *testing.T) {
+	loc := time.FixedZone("JST", 9*60*60)
+	buckets := []summary.Bucket{
+		{
+			Date:     "2026-01-10",
+			Total:    90 * time.Minute,
+			Billable: time.Hour,
+			Projects: []summary.ProjectBucket{
+				{Name: "Alpha", Total: 90 * time.Minute, Tasks: []summary.TaskBucket{{Name: "Design", Total: 90 * time.Minute}}},
+			},
+			Tasks: []summary.TaskSummary{{Name: "Design", Total: 90 * time.Minute}},
+		},
+		{
+			Date:  "2026-01-11",
+			Total: 20 * time.Minute,
+		},
+	}
+
+	payload := NewPayload(buckets, PayloadOptions{
+		RangeStart: time.Date(2026, 1, 10, 0, 0, 0, 0, loc),
+		RangeEnd:   time.Date(2026, 1, 12, 0, 0, 0, 0, loc),
+		Location:   loc,
+		Markdown:   "## 2026-01-10\n",
+	})
+
+	if payload.Range != (Range{Start: "2026-01-10", End: "2026-01-11", Timezone: "JST"}) {
+		t.Fatalf("unexpected range: %+v", payload.Range)
+	}
+	if payload.Total.Seconds != 6600 || payload.Total.Hours != 1.83 || payload.Total.BillableSeconds != 3600 {
+		t.Fatalf("unexpected total: %+v", payload.Total)
+	}
+	if len(payload.Buckets) != 2 || payload.Buckets[0].Projects[0].Tasks[0].Name != "Design" || payload.Buckets[0].Hours != 1.5 {
+		t.Fatalf("unexpected buckets: %+v", payload.Buckets)
+	}
+	if payload.Buckets[1].Projects == nil || payload.Buckets[1].Tasks == nil {
+		t.Fatalf("expected empty lists instead of null: %+v", payload.Buckets[1])
+	}
+}
+
+func TestRenderUsesTemplate(t *testing.T) {
+	payload := Payload{Range: Range{Start: "2026-01-10", End: "2026-01-10"}, Total: Total{Hours: 1.5}, Markdown: "- Alpha 1.50h\n"}
+
+	body, err := Render(`{"text": {{json .Markdown}}, "hours": {{.Total.Hours}}, "day": "{{.Range.Start}}"}`, payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, body)
+	}
+	if got["text"] != "- Alpha 1.50h\n" || got["hours"] != 1.5 || got["day"] != "2026-01-10" {
+		t.Fatalf("unexpected body: %v", got)
+	}
+
+	if _, err := Render(`{{.Missing`, payload); err == nil || !strings.Contains(err.Error(), "invalid webhook body template") {
+		t.Fatalf("expected template error, got %v", err)
+	}
+}
+
+func TestClientSignsAndRetries(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			t.Fatalf("missing custom header: %v", r.Header)
+		}
+		if r.Header.Get("X-Hub-Signature") != Sign("s3cret", body) {
+			t.Fatalf("unexpected signature: %s", r.Header.Get("X-Hub-Signature"))
+		}
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(Options{
+		URL:             server.URL,
+		Headers:         map[string]string{"Authorization": "Bearer abc"},
+		Secret:          "s3cret",
+		SignatureHeader: "X-Hub-Signature",
+		Retries:         2,
+		Backoff:         time.Millisecond,
+	}, server.Client())
+	if err := client.Send(context.Background(), []byte(`{"ok":true}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestClientDoesNotRetryClientErrors(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, "bad payload", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewClient(Options{URL: server.URL, Retries: 3, Backoff: time.Millisecond}, server.Client())
+	err := client.Send(context.Background(), []byte(`{}`))
+	if err == nil || !strings.Contains(err.Error(), "bad payload") {
+		t.Fatalf("expected client error, got %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected a single attempt, got %d", attempts)
+	}
+}
+
+func TestClientCapsRetriesAndBackoff(t *testing.T) {
+	client := NewClient(Options{URL: "https://example.com", Retries: 1000, Backoff: time.Hour}, nil)
+	if client.opts.Retries != MaxRetries {
+		t.Fatalf("expected retries capped at %d, got %d", MaxRetries, client.opts.Retries)
+	}
+	for _, attempt := range []int{1, 5, MaxRetries} {
+		if got := client.backoff(attempt); got != time.Minute {
+			t.Fatalf("attempt %d: expected backoff capped at 1m, got %s", attempt, got)
+		}
+	}
+
+	client = NewClient(Options{URL: "https://example.com", Retries: -1}, nil)
+	if client.opts.Retries != 0 {
+		t.Fatalf("expected negative retries clamped to 0, got %d", client.opts.Retries)
+	}
+
+	client = NewClient(Options{URL: "https://example.com", Backoff: time.Second}, nil)
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 32 * time.Second, time.Minute, time.Minute}
+	for i, attempt := range []int{1, 2, 3, 6, 7, 60} {
+		if got := client.backoff(attempt); got != want[i] {
+			t.Fatalf("attempt %d: expected %s, got %s", attempt, want[i], got)
+		}
+	}
+}
+
+func TestClientSetsContentTypeOnlyWhenConfigured(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	for _, contentType := range []string{"application/json", "text/plain; charset=utf-8", ""} {
+		client := NewClient(Options{URL: server.URL, ContentType: contentType}, server.Client())
+		if err := client.Send(context.Background(), []byte("hello")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	want := []string{"application/json", "text/plain; charset=utf-8", ""}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected content types: %q", got)
+	}
+}
+
+func TestClientSendsOnceWithNegativeRetries(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(Options{URL: server.URL, Retries: -1}, server.Client())
+	if err := client.Send(context.Background(), []byte(`{}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected a single attempt, got %d", attempts)
+	}
+}
+
+func TestSignMatchesKnownDigest(t *testing.T) {
+	got := Sign("key", []byte("The quick brown fox jumps over the lazy dog"))
+	want := "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+	if got != want {
+		t.Fatalf("unexpected signature: %s", got)
+	}
+}